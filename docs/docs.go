@@ -19,30 +19,215 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/subscriptions": {
+        "/analytics/churn": {
             "get": {
-                "description": "Lists subscription records",
+                "description": "Counts subscriptions active, started and ended in every month of the period. Records of the same\nuser and service following each other without a gap are counted as one subscription",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "analytics"
+                ],
+                "summary": "Subscription churn",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First month of the period, format: MM-YYYY, 11 months before to by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last month of the period, format: MM-YYYY, current month by default",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChurnResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/analytics/lifetime": {
+            "get": {
+                "description": "Averages number of months subscriptions to every service last, active ones up to the current month",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Average subscription lifetime",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service name for filtering",
+                        "name": "service_name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LifetimeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/analytics/spend-growth": {
+            "get": {
+                "description": "Computes spend of every month of the period and its change relative to the previous month",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Month-over-month spend growth",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First month of the period, format: MM-YYYY, 11 months before to by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last month of the period, format: MM-YYYY, current month by default",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SpendGrowthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/analytics/top-services": {
+            "get": {
+                "description": "Ranks services by sum of monthly charges over the period or by number of subscribed users",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Top services",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First month of the period, format: MM-YYYY, 11 months before to by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last month of the period, format: MM-YYYY, current month by default",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ranking field: spend (default) or subscribers",
+                        "name": "by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of services, from 1 to 100, 10 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TopServicesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit": {
+            "get": {
+                "description": "Returns audit log entries of all subscription records filtered by actor and time range",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Query audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Actor for filtering",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of time range (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of time range (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List subscription records",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.SubscriptionResponse"
+                                "$ref": "#/definitions/models.AuditEntryResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets": {
+            "get": {
+                "description": "Lists budgets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "List budgets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BudgetResponse"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Creates new subscription record",
+                "description": "Creates monthly budget scoped to user, service or user and service pair",
                 "consumes": [
                     "application/json"
                 ],
@@ -50,52 +235,82 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "budgets"
+                ],
+                "summary": "Create budget",
+                "parameters": [
+                    {
+                        "description": "Budget data",
+                        "name": "budget",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BudgetRequest"
+                        }
+                    }
                 ],
-                "summary": "Create new subscription record",
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.SubscriptionResponse"
+                            "$ref": "#/definitions/models.BudgetResponse"
                         }
                     }
                 }
             }
         },
-        "/subscriptions/total-cost": {
+        "/budgets/alerts": {
             "get": {
-                "description": "Calculating subscription cost based on filtering parametres",
+                "description": "Lists the latest alerts raised when writes of subscription records pushed budgets over their limit, newest first.\nBudget is alerted once for every month it becomes exceeded in, not on every write while it stays exceeded.\nWrite checks every month of the record period, up to the latest 12 of them, and each alert is also published as budget.exceeded outbox event",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "budgets"
                 ],
-                "summary": "Calculate subscriptin cost",
+                "summary": "List budget alerts",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Start date of period (MM-YYYY)",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date of period (MM-YYYY)",
-                        "name": "end_date",
+                        "type": "integer",
+                        "description": "Budget ID for filtering",
+                        "name": "budget_id",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Service name for filtering",
-                        "name": "service_name",
+                        "type": "integer",
+                        "description": "Number of alerts, from 1 to 100, 20 by default",
+                        "name": "limit",
                         "in": "query"
-                    },
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BudgetAlertResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/status": {
+            "get": {
+                "description": "Compares every budget against actual spend of the month, computed like subscription cost of that month, and flags overruns",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Compare budgets against spend",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "User UUID for filtering",
-                        "name": "user_id",
+                        "description": "Month to compare (MM-YYYY), current month by default",
+                        "name": "month",
                         "in": "query"
                     }
                 ],
@@ -103,26 +318,29 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SubscriptionCostResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BudgetStatusResponse"
+                            }
                         }
                     }
                 }
             }
         },
-        "/subscriptions/{id}": {
+        "/budgets/{id}": {
             "get": {
-                "description": "Gets subscription record by ID",
+                "description": "Gets budget by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "budgets"
                 ],
-                "summary": "Get subscription record by ID",
+                "summary": "Get budget by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Subscription ID",
+                        "description": "Budget ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -132,13 +350,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SubscriptionResponse"
+                            "$ref": "#/definitions/models.BudgetResponse"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Makes full update of subscription recored by ID",
+                "description": "Makes full update of budget by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -146,24 +364,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "budgets"
                 ],
-                "summary": "Update subscription recored by ID",
+                "summary": "Update budget by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Subscription ID",
+                        "description": "Budget ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "New data for subscription record",
-                        "name": "subscription",
+                        "description": "New data for budget",
+                        "name": "budget",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.SubscriptionRequest"
+                            "$ref": "#/definitions/models.BudgetRequest"
                         }
                     }
                 ],
@@ -171,21 +389,21 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SubscriptionResponse"
+                            "$ref": "#/definitions/models.BudgetResponse"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Deletes subscription record by ID",
+                "description": "Deletes budget by ID",
                 "tags": [
-                    "subscriptions"
+                    "budgets"
                 ],
-                "summary": "Delete subscription record by ID",
+                "summary": "Delete budget by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Subscription ID",
+                        "description": "Budget ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -196,9 +414,11 @@ const docTemplate = `{
                         "description": "No content"
                     }
                 }
-            },
-            "patch": {
-                "description": "Makes partial update of subscription record by ID",
+            }
+        },
+        "/graphql": {
+            "post": {
+                "description": "Executes GraphQL query or mutation over subscription records, users and costs. Schema is\navailable through introspection. Fields of list items, like cost of every record, are\nloaded for the whole list at once",
                 "consumes": [
                     "application/json"
                 ],
@@ -206,57 +426,2091 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "graphql"
                 ],
-                "summary": "Patch subscription record by ID",
+                "summary": "GraphQL endpoint",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subscription ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, server default if absent",
+                        "name": "date_format",
+                        "in": "query"
                     },
                     {
-                        "description": "Data for partial updating subscription record",
-                        "name": "subscription",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.SubscriptionRequest"
-                        }
+                        "type": "string",
+                        "description": "Format of dates in response, used if date_format query parameter is absent",
+                        "name": "X-Date-Format",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SubscriptionResponse"
+                            "type": "object"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.SubscriptionCostResponse": {
-            "description": "Response with total cost of subscription records",
-            "type": "object",
-            "properties": {
-                "cost": {
-                    "description": "@Description Integer total cost of subscription records\n@Exmaple 2344",
-                    "type": "integer"
-                }
-            }
         },
-        "models.SubscriptionRequest": {
-            "description": "Request to create or update subscription record",
-            "type": "object",
-            "properties": {
-                "end_date": {
-                    "description": "@Description Month and year of subsription end, format: MM-YYYY\n@Example 08-2025",
+        "/renewals/runs": {
+            "get": {
+                "description": "Lists the latest runs of the job ending expired subscriptions and renewing auto-renewing ones, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "renewals"
+                ],
+                "summary": "List renewal job runs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of runs, from 1 to 100, 20 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RenewalRunResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/services": {
+            "get": {
+                "description": "Lists services of subscription records and services with assigned category, with their categories",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "List services",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category for filtering, uncategorized for services without category",
+                        "name": "category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ServiceCategoryResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{service_name}/category": {
+            "put": {
+                "description": "Assigns category to service, replacing previous one. Categories are case insensitive",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Set service category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service name",
+                        "name": "service_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Category",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceCategoryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceCategoryResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes category of service, so it becomes uncategorized",
+                "tags": [
+                    "services"
+                ],
+                "summary": "Delete service category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service name",
+                        "name": "service_name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    }
+                }
+            }
+        },
+        "/subscriptions": {
+            "get": {
+                "description": "Lists subscription records. Besides JSON, records can be exported as CSV, NDJSON or XLSX\nchosen by format query parameter or Accept header, such exports are streamed row by row",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List subscription records",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Moment to list subscription records as they stood at (RFC 3339)",
+                        "name": "as_of",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Tag listed records must be marked with, repeat to require several tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cost center of listed records",
+                        "name": "cost_center",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Response format: json (default), csv, ndjson or xlsx",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response, used if date_format query parameter is absent",
+                        "name": "X-Date-Format",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SubscriptionResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates new subscription record",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Create new subscription record",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response, used if date_format query parameter is absent",
+                        "name": "X-Date-Format",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SubscriptionResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Record overlaps another record of the same user and service in strict overlap mode",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/batch": {
+            "post": {
+                "description": "Creates many subscription records at once. In atomic mode nothing is created if any record fails, in partial mode every record is created independently",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Create subscription records in batch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Batch mode: atomic (default) or partial",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Subscription records to create",
+                        "name": "subscriptions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SubscriptionRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Batch has more records than allowed or its body is too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/cost-breakdown": {
+            "get": {
+                "description": "Calculating subscription cost per service, category, cost center or tag based on filtering parametres.\nRecords without cost center or tags are grouped under empty key, record with several tags counts for each of them.\nBesides JSON, breakdown can be exported as CSV, NDJSON or XLSX chosen by format query parameter or Accept header",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Calculate subscription cost breakdown",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day of period (MM-YYYY or YYYY-MM-DD), start of every record by default",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day of period (MM-YYYY or YYYY-MM-DD), current date by default",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service name for filtering",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service category for filtering, uncategorized for services without category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User UUID for filtering",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Moment to calculate cost as data stood at (RFC 3339)",
+                        "name": "as_of",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grouping key: service_name (default), category, cost_center or tag",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Response format: json (default), csv, ndjson or xlsx",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SubscriptionCostBreakdownResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/events": {
+            "get": {
+                "description": "Server-Sent Events stream of subscription record changes. Event id is outbox sequence number,\nreconnecting client passing it in Last-Event-ID header (or last_event_id parameter) receives every missed event",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Stream subscription changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID for filtering",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service name for filtering",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Sequence number to resume after, used when Last-Event-ID header is absent",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/forecast": {
+            "get": {
+                "description": "Projects month-by-month cost of the next N months from active subscriptions, their known end dates\nand records starting in the future, e.g. scheduled price changes, broken down by service",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Forecast subscription spend",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of months to forecast, from 1 to 60, 12 by default",
+                        "name": "months",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ForecastResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/import": {
+            "post": {
+                "description": "Streams CSV file with header containing service_name, price, user_id, start_date and optional end_date columns.\nRows are written in chunks, rows duplicating existing records or previous rows are skipped. In dry run rows\nare only validated, duplicates of previous rows are detected among the first 100000 unique rows and\nduplicates_incomplete is set if there were more. If import stops on error, response has the error along\nwith counts of rows processed before it, records of saved chunks are kept",
+                "consumes": [
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Import subscription records from CSV",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Validate rows without writing them",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "CSV is malformed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Import stopped by failure",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/merge": {
+            "post": {
+                "description": "Combines overlapping or consecutive records of the same user, service, price and billing day into the record\nwith the lowest ID covering their unified period, other records are deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Merge subscription records",
+                "parameters": [
+                    {
+                        "description": "Records to merge",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MergeRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response, used if date_format query parameter is absent",
+                        "name": "X-Date-Format",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SubscriptionResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Record with one of ids not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Records can not be merged",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/overlaps": {
+            "get": {
+                "description": "Finds pairs of subscription records of the same user and service with overlapping periods,\nwith overlap period and amount charged twice because of it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List overlapping subscription records",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID for filtering",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service name for filtering",
+                        "name": "service_name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OverlapResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/total-cost": {
+            "get": {
+                "description": "Calculating subscription cost based on filtering parametres. Every record is charged its price\non each of its billing days within the period\nShared subscriptions are split between members by weight, so filtering by user counts only user's share\nAlong with total cost returns totals per service category",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Calculate subscriptin cost",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day of period (MM-YYYY or YYYY-MM-DD), start of every record by default",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day of period (MM-YYYY or YYYY-MM-DD), current date by default",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service name for filtering",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service category for filtering, uncategorized for services without category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User UUID for filtering",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Moment to calculate cost as data stood at (RFC 3339)",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SubscriptionCostResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}": {
+            "get": {
+                "description": "Gets subscription record by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscription record by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response, used if date_format query parameter is absent",
+                        "name": "X-Date-Format",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SubscriptionResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Makes full update of subscription recored by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Update subscription recored by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New data for subscription record",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SubscriptionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response, used if date_format query parameter is absent",
+                        "name": "X-Date-Format",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SubscriptionResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Record overlaps another record of the same user and service in strict overlap mode",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes subscription record by ID",
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Delete subscription record by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Makes partial update of subscription record by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Patch subscription record by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data for partial updating subscription record",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SubscriptionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response, used if date_format query parameter is absent",
+                        "name": "X-Date-Format",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SubscriptionResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Record overlaps another record of the same user and service in strict overlap mode",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/history": {
+            "get": {
+                "description": "Returns every change of subscription record by ID in chronological order, including deletion",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Get change history of subscription record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEntryResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/split": {
+            "post": {
+                "description": "Ends subscription record the day before given date and creates record continuing it\nfrom that date on the same billing day, e.g. to record price change. Month means its first day",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Split subscription record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Date to split at and new price",
+                        "name": "split",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SplitRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response, used if date_format query parameter is absent",
+                        "name": "X-Date-Format",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SubscriptionResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Record not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Record can not be split at this date",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Lists tags used on subscription records with number of records marked with each of them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "List tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TagResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/merge": {
+            "post": {
+                "description": "Replaces given tags with another one across all subscription records, renaming is merge of a single tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Rename or merge tags",
+                "parameters": [
+                    {
+                        "description": "Tags to replace and replacement",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TagMergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TagMergeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Lists users in order of creation",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates user subscription records can refer to",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create user",
+                "parameters": [
+                    {
+                        "description": "User data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "User with the same id or email already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}": {
+            "get": {
+                "description": "Gets user by UUID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces profile data of user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "User with the same email already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes user with budgets. By default user with subscription records or sharing records of others is not deleted,\nwith cascade=soft_delete records are moved to deleted_subscription_record table and deleted like through DELETE /subscriptions/{id}\nand user is removed from members of shared records",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "What to do with subscription records: block (default) or soft_delete",
+                        "name": "cascade",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "User has or shares subscription records",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/charges.ics": {
+            "get": {
+                "description": "Returns RFC 5545 calendar with monthly recurring event on billing date of every active subscription of user\nand an event on end date of subscriptions that end",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get calendar of upcoming subscription charges",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "iCalendar feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/summary": {
+            "get": {
+                "description": "Returns active subscriptions of user with their monthly cost, spend this year, the most expensive service,\nsubscriptions ending soon and total spend over all time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get user subscription summary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of months after the current one to report ending subscriptions for, from 0 to 12, 1 by default",
+                        "name": "ending_within",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format of dates in response, used if date_format query parameter is absent",
+                        "name": "X-Date-Format",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserSummaryResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Lists registered webhooks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registers URL to receive HMAC-signed notifications about renewing and ending subscriptions.\nSecret is returned only in this response",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Register webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook data",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Gets webhook by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Makes full update of webhook by ID, secret is kept if it is empty",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update webhook by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New data for webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes webhook by ID together with its delivery log",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Returns delivery log of webhook, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDeliveryResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AuditEntryResponse": {
+            "description": "Audit log entry describing a single change of subscription record",
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "@Description Who made the change, taken from X-Actor header\n@Example alice",
+                    "type": "string"
+                },
+                "after": {
+                    "description": "@Description State of subscription record after the change",
+                    "type": "object"
+                },
+                "before": {
+                    "description": "@Description State of subscription record before the change",
+                    "type": "object"
+                },
+                "changed_at": {
+                    "description": "@Description Time of change in RFC 3339 format\n@Example 2025-07-01T12:00:00Z",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "@Description Integer ID of audit entry\n@Example 1",
+                    "type": "integer"
+                },
+                "operation": {
+                    "description": "@Description Kind of change: create, update or delete\n@Example update",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "@Description ID of request that made the change, taken from X-Request-ID header\n@Example 0b7c6a4e-5f3e-4a8e-9b0e-3f1d2c4b5a69",
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "description": "@Description Integer ID of changed subscription record\n@Example 1",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BatchItemResponse": {
+            "description": "Result of creating single subscription record of a batch",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "@Description Error that prevented creating subscription record\n@Example Invalid date format, should be like MM-YYYY",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "@Description Integer ID of created subscription record\n@Example 1",
+                    "type": "integer"
+                },
+                "index": {
+                    "description": "@Description Position of subscription record in request array\n@Example 0",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BatchResponse": {
+            "description": "Response with per-item results of batch creation",
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "@Description Number of created subscription records\n@Example 2",
+                    "type": "integer"
+                },
+                "failed": {
+                    "description": "@Description Number of failed subscription records\n@Example 0",
+                    "type": "integer"
+                },
+                "items": {
+                    "description": "@Description Per-item results in the order of request array",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchItemResponse"
+                    }
+                },
+                "mode": {
+                    "description": "@Description Batch mode: atomic or partial\n@Example atomic",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BudgetAlertResponse": {
+            "description": "Alert raised when write of subscription record pushed budget over its limit",
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "@Description Actor who made the write\n@Example admin",
+                    "type": "string"
+                },
+                "budget_id": {
+                    "description": "@Description Integer ID of exceeded budget\n@Example 1",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "@Description Moment alert was raised at\n@Example 2025-07-01T00:00:00Z",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "@Description Integer ID of alert\n@Example 1",
+                    "type": "integer"
+                },
+                "month": {
+                    "description": "@Description Month and year budget is exceeded in, format: MM-YYYY\n@Example 07-2025",
+                    "type": "string"
+                },
+                "monthly_limit": {
+                    "description": "@Description Monthly spend limit at the moment of alert\n@Example 1000",
+                    "type": "integer"
+                },
+                "request_id": {
+                    "description": "@Description ID of request that made the write\n@Example 5f2b8c1e-8d4a-4c1b-9a57-0f6d1c3e2a10",
+                    "type": "string"
+                },
+                "spend": {
+                    "description": "@Description Spend of the month right after the write\n@Example 1200",
+                    "type": "integer"
+                },
+                "subscription_id": {
+                    "description": "@Description Integer ID of subscription record whose write exceeded budget\n@Example 1",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BudgetRequest": {
+            "description": "Request to create or update monthly budget, scoped to user, service or both",
+            "type": "object",
+            "properties": {
+                "monthly_limit": {
+                    "description": "@Description Monthly spend limit (integer number of rubles)\n@Example 1000",
+                    "type": "integer"
+                },
+                "service_name": {
+                    "description": "@Description Service Name the budget applies to\n@Example Yandex Plus",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "@Description User's UUID the budget applies to\n@Example 60601fee-2bf1-4721-ae6f-7636e79a0cba",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BudgetResponse": {
+            "description": "Response with information about budget",
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "@Description Integer ID of budget\n@Example 1",
+                    "type": "integer"
+                },
+                "monthly_limit": {
+                    "description": "@Description Monthly spend limit (integer number of rubles)\n@Example 1000",
+                    "type": "integer"
+                },
+                "service_name": {
+                    "description": "@Description Service Name the budget applies to\n@Example Yandex Plus",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "@Description User's UUID the budget applies to\n@Example 60601fee-2bf1-4721-ae6f-7636e79a0cba",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BudgetStatusResponse": {
+            "description": "Comparison of budget against actual spend of a month",
+            "type": "object",
+            "properties": {
+                "exceeded": {
+                    "description": "@Description Whether spend exceeds limit\n@Example true",
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "@Description Integer ID of budget\n@Example 1",
+                    "type": "integer"
+                },
+                "month": {
+                    "description": "@Description Month and year of comparison, format: MM-YYYY\n@Example 07-2025",
+                    "type": "string"
+                },
+                "monthly_limit": {
+                    "description": "@Description Monthly spend limit (integer number of rubles)\n@Example 1000",
+                    "type": "integer"
+                },
+                "remaining": {
+                    "description": "@Description Limit left for the month, negative when budget is overrun\n@Example -200",
+                    "type": "integer"
+                },
+                "service_name": {
+                    "description": "@Description Service Name the budget applies to\n@Example Yandex Plus",
+                    "type": "string"
+                },
+                "spend": {
+                    "description": "@Description Actual spend of the month within budget scope\n@Example 1200",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "description": "@Description User's UUID the budget applies to\n@Example 60601fee-2bf1-4721-ae6f-7636e79a0cba",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChurnResponse": {
+            "description": "Response with new and ended subscriptions per month",
+            "type": "object",
+            "properties": {
+                "months": {
+                    "description": "@Description Months of the period in chronological order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MonthlyChurnResponse"
+                    }
+                }
+            }
+        },
+        "models.ForecastMonthResponse": {
+            "description": "Projected cost of a single month",
+            "type": "object",
+            "properties": {
+                "month": {
+                    "description": "@Description Month and year, format: MM-YYYY\n@Example 08-2025",
+                    "type": "string"
+                },
+                "services": {
+                    "description": "@Description Projected cost per service, most expensive first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SubscriptionCostItemResponse"
+                    }
+                },
+                "total": {
+                    "description": "@Description Integer projected total cost of the month\n@Example 1197",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ForecastResponse": {
+            "description": "Response with month-by-month spend forecast",
+            "type": "object",
+            "properties": {
+                "months": {
+                    "description": "@Description Projected months, starting with the next one",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ForecastMonthResponse"
+                    }
+                }
+            }
+        },
+        "models.ImportResponse": {
+            "description": "Response with results of CSV import",
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "@Description Number of created subscription records, or records that would be created in dry run\n@Example 1",
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "description": "@Description Whether import only validated rows without writing them\n@Example false",
+                    "type": "boolean"
+                },
+                "duplicates": {
+                    "description": "@Description Number of rows skipped as duplicates of existing records or of previous rows\n@Example 1",
+                    "type": "integer"
+                },
+                "duplicates_incomplete": {
+                    "description": "@Description Whether dry run had too many rows to detect all duplicates of previous rows, so real import may skip more\n@Example false",
+                    "type": "boolean"
+                },
+                "error": {
+                    "description": "@Description Error that stopped import, records counted as created are saved, rows after the last saved chunk are not\n@Example Failed to check duplicates",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "@Description Row-level errors, at most first 1000 are reported",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportRowError"
+                    }
+                },
+                "errors_truncated": {
+                    "description": "@Description Whether some row-level errors were omitted from response\n@Example false",
+                    "type": "boolean"
+                },
+                "failed": {
+                    "description": "@Description Number of rejected rows\n@Example 1",
+                    "type": "integer"
+                },
+                "rows": {
+                    "description": "@Description Number of processed data rows\n@Example 3",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportRowError": {
+            "description": "Validation error of single CSV row",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "@Description Reason why row was rejected\n@Example Invalid date format, should be like MM-YYYY",
+                    "type": "string"
+                },
+                "row": {
+                    "description": "@Description Number of row in CSV file, header is row 1\n@Example 2",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LifetimeResponse": {
+            "description": "Response with average subscription lifetime per service",
+            "type": "object",
+            "properties": {
+                "services": {
+                    "description": "@Description Services in alphabetical order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ServiceLifetimeResponse"
+                    }
+                }
+            }
+        },
+        "models.MergeRequest": {
+            "description": "Request to merge subscription records",
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "@Description IDs of subscription records of the same user, service, price and billing day to merge\n@Example [1, 2]",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.MonthlyChurnResponse": {
+            "description": "Subscriptions started and ended in a month",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "@Description Number of subscriptions active in the month\n@Example 12",
+                    "type": "integer"
+                },
+                "churn_rate": {
+                    "description": "@Description Share of active subscriptions ended in the month in percent, null if none were active\n@Example 8.33",
+                    "type": "number"
+                },
+                "ended": {
+                    "description": "@Description Number of subscriptions whose last month is this one\n@Example 1",
+                    "type": "integer"
+                },
+                "month": {
+                    "description": "@Description Month and year, format: MM-YYYY\n@Example 08-2025",
+                    "type": "string"
+                },
+                "started": {
+                    "description": "@Description Number of subscriptions whose first month is this one\n@Example 2",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MonthlySpendResponse": {
+            "description": "Spend of a month compared to the previous one",
+            "type": "object",
+            "properties": {
+                "change": {
+                    "description": "@Description Difference with spend of the previous month\n@Example 399",
+                    "type": "integer"
+                },
+                "growth": {
+                    "description": "@Description Growth relative to the previous month in percent, null if previous month had no spend\n@Example 50",
+                    "type": "number"
+                },
+                "month": {
+                    "description": "@Description Month and year, format: MM-YYYY\n@Example 08-2025",
+                    "type": "string"
+                },
+                "spend": {
+                    "description": "@Description Integer sum of charges of the month\n@Example 1197",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OverlapResponse": {
+            "description": "Pair of subscription records of the same user and service with overlapping periods",
+            "type": "object",
+            "properties": {
+                "double_charged": {
+                    "description": "@Description Amount charged twice: charges made twice multiplied by the lower price\n@Example 798",
+                    "type": "integer"
+                },
+                "end_date": {
+                    "description": "@Description Month and year overlap ends, format: MM-YYYY. Absent when both records are open-ended\n@Example 08-2025",
+                    "type": "string"
+                },
+                "first_id": {
+                    "description": "@Description Integer ID of the earlier created subscription record\n@Example 1",
+                    "type": "integer"
+                },
+                "months": {
+                    "description": "@Description Number of charges made twice up to now or to the end of overlap: billing dates within the overlap of the record charged less often\n@Example 2",
+                    "type": "integer"
+                },
+                "second_id": {
+                    "description": "@Description Integer ID of the later created subscription record\n@Example 2",
+                    "type": "integer"
+                },
+                "service_name": {
+                    "description": "@Description Service Name\n@Example Yandex Plus",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "@Description Month and year overlap starts, format: MM-YYYY\n@Example 07-2025",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "@Description User's UUID\n@Example 60601fee-2bf1-4721-ae6f-7636e79a0cba",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RenewalRunResponse": {
+            "description": "Outcome of a run of subscription expiry and renewal job",
+            "type": "object",
+            "properties": {
+                "conflicting_ids": {
+                    "description": "@Description IDs of auto-renewing subscription records not extended because extension would overlap another record\n@Description of the same user and service, their auto-renew is turned off and they are marked as ended\n@Example [7]",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "ended": {
+                    "description": "@Description Number of subscription records marked as ended\n@Example 3",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "@Description Error the run failed with, nothing is changed by failed run\n@Example context deadline exceeded",
+                    "type": "string"
+                },
+                "finished_at": {
+                    "description": "@Description Moment run finished at\n@Example 2025-07-01T00:00:01Z",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "@Description Run ID\n@Example 1",
+                    "type": "integer"
+                },
+                "renewed": {
+                    "description": "@Description Number of subscription records extended by one billing period\n@Example 2",
+                    "type": "integer"
+                },
+                "started_at": {
+                    "description": "@Description Moment run started at\n@Example 2025-07-01T00:00:00Z",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ServiceCategoryRequest": {
+            "description": "Request to assign category to service",
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "@Description Category of service, e.g. music, video, cloud or productivity\n@Example music",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ServiceCategoryResponse": {
+            "description": "Service with its category",
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "@Description Category of service, uncategorized if none was assigned\n@Example music",
+                    "type": "string"
+                },
+                "service_name": {
+                    "description": "@Description Name of the service\n@Example Yandex Plus",
+                    "type": "string"
+                },
+                "subscriptions": {
+                    "description": "@Description Number of subscription records of the service\n@Example 12",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ServiceLifetimeResponse": {
+            "description": "Average lifetime of subscriptions to a service",
+            "type": "object",
+            "properties": {
+                "average_months": {
+                    "description": "@Description Average number of months subscription lasts, active ones counted up to the current month\n@Example 7.4",
+                    "type": "number"
+                },
+                "service_name": {
+                    "description": "@Description Name of the service\n@Example Yandex Plus",
+                    "type": "string"
+                },
+                "subscriptions": {
+                    "description": "@Description Number of subscriptions, continuous records of the same user counted once\n@Example 5",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ServiceSpendResponse": {
+            "description": "Spend and subscriber count of a service over the period",
+            "type": "object",
+            "properties": {
+                "service_name": {
+                    "description": "@Description Name of the service\n@Example Yandex Plus",
+                    "type": "string"
+                },
+                "spend": {
+                    "description": "@Description Integer sum of monthly charges of the service over the period\n@Example 4788",
+                    "type": "integer"
+                },
+                "subscribers": {
+                    "description": "@Description Number of distinct users subscribed to the service during the period\n@Example 3",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SpendGrowthResponse": {
+            "description": "Response with month-over-month spend growth",
+            "type": "object",
+            "properties": {
+                "months": {
+                    "description": "@Description Months of the period in chronological order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MonthlySpendResponse"
+                    }
+                }
+            }
+        },
+        "models.SplitRequest": {
+            "description": "Request to split subscription record",
+            "type": "object",
+            "properties": {
+                "month": {
+                    "description": "@Description Date the second part starts from, format: MM-YYYY or YYYY-MM-DD, month means its first day\n@Example 09-2025",
+                    "type": "string"
+                },
+                "price": {
+                    "description": "@Description New price of the second part, the same as original if absent\n@Example 499",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SubscriptionCostBreakdownResponse": {
+            "description": "Response with cost of subscription records broken down by grouping key",
+            "type": "object",
+            "properties": {
+                "group_by": {
+                    "description": "@Description Field subscription records are grouped by\n@Example service_name",
+                    "type": "string"
+                },
+                "items": {
+                    "description": "@Description Cost of every group, most expensive first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SubscriptionCostItemResponse"
+                    }
+                }
+            }
+        },
+        "models.SubscriptionCostItemResponse": {
+            "description": "Cost of subscription records sharing the same value of grouping key",
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "description": "@Description Integer total cost of subscription records in group\n@Example 798",
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "@Description Value of grouping key\n@Example Yandex Plus",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SubscriptionCostResponse": {
+            "description": "Response with total cost of subscription records",
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "description": "@Description Total cost per service category, most expensive first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SubscriptionCostItemResponse"
+                    }
+                },
+                "cost": {
+                    "description": "@Description Integer total cost of subscription records\n@Exmaple 2344",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SubscriptionMemberRequest": {
+            "description": "Member sharing cost of subscription",
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "description": "@Description Member's UUID\n@Example 60601fee-2bf1-4721-ae6f-7636e79a0cba",
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "@Description Positive integer weight of member's share, 1 if absent, so equal weights split cost equally\n@Example 1",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SubscriptionMemberResponse": {
+            "description": "Member sharing cost of subscription",
+            "type": "object",
+            "properties": {
+                "share": {
+                    "description": "@Description Part of price member pays\n@Example 0.5",
+                    "type": "number"
+                },
+                "user_id": {
+                    "description": "@Description Member's UUID\n@Example 60601fee-2bf1-4721-ae6f-7636e79a0cba",
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "@Description Integer weight of member's share\n@Example 1",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SubscriptionRequest": {
+            "description": "Request to create or update subscription record",
+            "type": "object",
+            "properties": {
+                "auto_renew": {
+                    "description": "@Description Extend subscription by one billing period when it ends. Absent keeps current value on partial update\n@Example true",
+                    "type": "boolean"
+                },
+                "billing_day": {
+                    "description": "@Description Day of month subscription is charged on, the last day in shorter months. Day of start by default\n@Example 17",
+                    "type": "integer"
+                },
+                "cost_center": {
+                    "description": "@Description Cost center the subscription is paid from, empty string clears it on partial update\n@Example marketing",
                     "type": "string"
                 },
+                "end_date": {
+                    "description": "@Description Last day of subscription, format: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY, month name with year or YYYY-MM-DD, month means its last day\n@Example 08-2025",
+                    "type": "string"
+                },
+                "members": {
+                    "description": "@Description Members sharing cost of subscription, the owner pays the whole price if empty. Absent keeps current members on update",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SubscriptionMemberRequest"
+                    }
+                },
                 "price": {
                     "description": "@Description Subscription price (integer number of rubles)\n@Exmaple 399",
                     "type": "integer"
@@ -266,9 +2520,16 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "start_date": {
-                    "description": "@Description Month and year of subscription srart, format: MM-YYYY\n@Example 07-2025",
+                    "description": "@Description Subscription start, format: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY, month name with year or YYYY-MM-DD, month means its first day\n@Example 07-2025",
                     "type": "string"
                 },
+                "tags": {
+                    "description": "@Description Free-form tags, e.g. projects the subscription is used for\n@Example [\"project-apollo\", \"design\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "user_id": {
                     "description": "@Description User's UUID\n@Example 60601fee-2bf1-4721-ae6f-7636e79a0cba",
                     "type": "string"
@@ -279,14 +2540,33 @@ const docTemplate = `{
             "description": "Response with information about subscription",
             "type": "object",
             "properties": {
+                "auto_renew": {
+                    "description": "@Description Whether subscription is extended by one billing period when it ends\n@Example false",
+                    "type": "boolean"
+                },
+                "billing_day": {
+                    "description": "@Description Day of month subscription is charged on\n@Example 1",
+                    "type": "integer"
+                },
+                "cost_center": {
+                    "description": "@Description Cost center the subscription is paid from\n@Example marketing",
+                    "type": "string"
+                },
                 "end_date": {
-                    "description": "@Description Month and year of subsription end, format: MM-YYYY\n@Example 08-2025",
+                    "description": "@Description Last day of subscription, in requested format, MM-YYYY by default\n@Example 08-2025",
                     "type": "string"
                 },
                 "id": {
                     "description": "@Description Integer ID of subscription record\n@Example 1",
                     "type": "integer"
                 },
+                "members": {
+                    "description": "@Description Members sharing cost of subscription, absent if the owner pays the whole price",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SubscriptionMemberResponse"
+                    }
+                },
                 "price": {
                     "description": "@Description Subscription price (integer number of rubles)\n@Exmaple 399",
                     "type": "integer"
@@ -296,12 +2576,288 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "start_date": {
-                    "description": "@Description Month and year of subscription srart, format: MM-YYYY\n@Example 07-2025",
+                    "description": "@Description Subscription start, in requested format, MM-YYYY by default\n@Example 07-2025",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "@Description Status of subscription: active or ended once its end date has passed\n@Example active",
                     "type": "string"
                 },
+                "tags": {
+                    "description": "@Description Free-form tags\n@Example [\"project-apollo\", \"design\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "user_id": {
                     "description": "@Description User's UUID\n@Example 60601fee-2bf1-4721-ae6f-7636e79a0cba",
                     "type": "string"
+                },
+                "warnings": {
+                    "description": "@Description Warnings about the change, e.g. exceeded budgets\n@Example [\"Budget 1 is exceeded for 07-2025: spend 1200 of 1000\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.TagMergeRequest": {
+            "description": "Request to rename tag or merge several tags into one across all subscription records",
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "@Description Tags to replace\n@Example [\"apollo\", \"project-apolo\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "to": {
+                    "description": "@Description Tag to replace them with, may be one of existing tags\n@Example project-apollo",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TagMergeResponse": {
+            "description": "Response with result of tag rename or merge",
+            "type": "object",
+            "properties": {
+                "to": {
+                    "description": "@Description Tag the others were replaced with\n@Example project-apollo",
+                    "type": "string"
+                },
+                "updated": {
+                    "description": "@Description Number of changed subscription records\n@Example 3",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TagResponse": {
+            "description": "Tag with number of subscription records marked with it",
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "@Description Number of subscription records marked with tag\n@Example 4",
+                    "type": "integer"
+                },
+                "tag": {
+                    "description": "@Description Tag\n@Example project-apollo",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TopServicesResponse": {
+            "description": "Response with top services of the period",
+            "type": "object",
+            "properties": {
+                "by": {
+                    "description": "@Description Field services are ranked by\n@Example spend",
+                    "type": "string"
+                },
+                "from": {
+                    "description": "@Description First month of the period, format: MM-YYYY\n@Example 01-2025",
+                    "type": "string"
+                },
+                "services": {
+                    "description": "@Description Services, best ranked first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ServiceSpendResponse"
+                    }
+                },
+                "to": {
+                    "description": "@Description Last month of the period, format: MM-YYYY\n@Example 12-2025",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserRequest": {
+            "description": "Request to create or update user",
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "@Description Unique email of user\n@Example ivan@example.com",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "@Description User UUID, generated if absent on creation and ignored on update\n@Example 60601fee-2bf1-4721-ae6f-7636e79a0cba",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "@Description Name of user\n@Example Ivan Ivanov",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserResponse": {
+            "description": "Response with information about user",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "@Description Moment user was created\n@Example 2025-08-01T12:00:00Z",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "@Description Unique email of user\n@Example ivan@example.com",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "@Description User UUID\n@Example 60601fee-2bf1-4721-ae6f-7636e79a0cba",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "@Description Name of user\n@Example Ivan Ivanov",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "@Description Moment user was last updated\n@Example 2025-08-01T12:00:00Z",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserSummaryResponse": {
+            "description": "Summary of user subscriptions and spend",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "@Description Subscription records active in the current month",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SubscriptionResponse"
+                    }
+                },
+                "ending_soon": {
+                    "description": "@Description Active subscription records whose last month is within the window",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SubscriptionResponse"
+                    }
+                },
+                "lifetime_total": {
+                    "description": "@Description Integer sum of all monthly charges up to the current month\n@Example 11571",
+                    "type": "integer"
+                },
+                "month": {
+                    "description": "@Description Current month summary is computed for, in requested format, MM-YYYY by default\n@Example 08-2025",
+                    "type": "string"
+                },
+                "monthly_spend": {
+                    "description": "@Description Integer cost of subscriptions active in the current month, user's share for shared ones\n@Example 798",
+                    "type": "integer"
+                },
+                "most_expensive_service": {
+                    "description": "@Description Active service with the highest monthly cost, null if there are no active subscriptions",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SubscriptionCostItemResponse"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "description": "@Description User UUID\n@Example 60601fee-2bf1-4721-ae6f-7636e79a0cba",
+                    "type": "string"
+                },
+                "year_spend": {
+                    "description": "@Description Integer sum of monthly charges from January up to the current month\n@Example 6384",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookDeliveryResponse": {
+            "description": "Delivery log entry of webhook notification",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "@Description Number of delivery attempts made\n@Example 1",
+                    "type": "integer"
+                },
+                "delivered_at": {
+                    "description": "@Description Time of successful delivery in RFC 3339 format\n@Example 2025-07-29T12:00:00Z",
+                    "type": "string"
+                },
+                "event": {
+                    "description": "@Description Notified event\n@Example subscription.renewing",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "@Description Integer ID of delivery\n@Example 1",
+                    "type": "integer"
+                },
+                "last_error": {
+                    "description": "@Description Error of the last failed attempt\n@Example unexpected status code 500",
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "description": "@Description HTTP status code of the last attempt\n@Example 200",
+                    "type": "integer"
+                },
+                "occurs_on": {
+                    "description": "@Description Date the renewal or end happens on, format: YYYY-MM-DD\n@Example 2025-08-01",
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "@Description Sent payload",
+                    "type": "object"
+                },
+                "status": {
+                    "description": "@Description Delivery status: pending, delivered or failed\n@Example delivered",
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "description": "@Description Integer ID of subscription record the event is about\n@Example 1",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookRequest": {
+            "description": "Request to register or update webhook",
+            "type": "object",
+            "properties": {
+                "events": {
+                    "description": "@Description Events to notify about: subscription.renewing, subscription.ending. All events if empty\n@Example [\"subscription.renewing\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "description": "@Description Secret used to sign payloads with HMAC-SHA256, generated on creation and kept on update if empty\n@Example s3cr3t",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "@Description URL notifications are posted to\n@Example https://example.com/hooks/subscriptions",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookResponse": {
+            "description": "Response with information about webhook",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "@Description Time of registration in RFC 3339 format\n@Example 2025-07-01T12:00:00Z",
+                    "type": "string"
+                },
+                "events": {
+                    "description": "@Description Events to notify about, all events if empty\n@Example [\"subscription.renewing\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "description": "@Description Integer ID of webhook\n@Example 1",
+                    "type": "integer"
+                },
+                "secret": {
+                    "description": "@Description Secret used to sign payloads, returned only when webhook is created\n@Example s3cr3t",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "@Description URL notifications are posted to\n@Example https://example.com/hooks/subscriptions",
+                    "type": "string"
                 }
             }
         }
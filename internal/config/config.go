@@ -3,6 +3,8 @@ package config
 import (
 	"log/slog"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -12,6 +14,25 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	ServerPort string
+	GRPCPort   string
+
+	StrictOverlap      bool
+	AutoProvisionUsers bool
+	DateFormat         string
+
+	NotifyInterval     time.Duration
+	NotifyWindow       time.Duration
+	NotifyMaxAttempts  int
+	NotifyRetryBackoff time.Duration
+
+	OutboxSink       string
+	OutboxFile       string
+	OutboxWebhookURL string
+	OutboxInterval   time.Duration
+
+	RenewalInterval time.Duration
+
+	LegacyAPISunset time.Time
 }
 
 func Load(log *slog.Logger) *Config {
@@ -22,6 +43,25 @@ func Load(log *slog.Logger) *Config {
 		DBPassword: getEnv("DB_PASSWORD", "postgres"),
 		DBName:     getEnv("DB_NAME", "Effective-Mobile-Test"),
 		ServerPort: getEnv("SERVER_PORT", "8080"),
+		GRPCPort:   getEnv("GRPC_PORT", "9090"),
+
+		StrictOverlap:      getEnvBool(log, "STRICT_OVERLAP", false),
+		AutoProvisionUsers: getEnvBool(log, "AUTO_PROVISION_USERS", false),
+		DateFormat:         getEnv("DATE_FORMAT", "MM-YYYY"),
+
+		NotifyInterval:     getEnvDuration(log, "NOTIFY_INTERVAL", time.Minute),
+		NotifyWindow:       getEnvDuration(log, "NOTIFY_WINDOW", 72*time.Hour),
+		NotifyMaxAttempts:  getEnvInt(log, "NOTIFY_MAX_ATTEMPTS", 5),
+		NotifyRetryBackoff: getEnvDuration(log, "NOTIFY_RETRY_BACKOFF", 30*time.Second),
+
+		OutboxSink:       getEnv("OUTBOX_SINK", "none"),
+		OutboxFile:       getEnv("OUTBOX_FILE", "events.ndjson"),
+		OutboxWebhookURL: getEnv("OUTBOX_WEBHOOK_URL", ""),
+		OutboxInterval:   getEnvDuration(log, "OUTBOX_INTERVAL", time.Second),
+
+		RenewalInterval: getEnvDuration(log, "RENEWAL_INTERVAL", time.Hour),
+
+		LegacyAPISunset: getEnvDate(log, "LEGACY_API_SUNSET", time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC)),
 	}
 }
 
@@ -32,3 +72,63 @@ func getEnv(key, defaultValue string) string {
 
 	return defaultValue
 }
+
+func getEnvDuration(log *slog.Logger, key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Warn("Invalid duration in environment, using default", "key", key, "value", value, "default", defaultValue)
+		return defaultValue
+	}
+
+	return duration
+}
+
+func getEnvDate(log *slog.Logger, key string, defaultValue time.Time) time.Time {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		log.Warn("Invalid date in environment, using default", "key", key, "value", value, "default", defaultValue.Format(time.DateOnly))
+		return defaultValue
+	}
+
+	return date
+}
+
+func getEnvInt(log *slog.Logger, key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		log.Warn("Invalid number in environment, using default", "key", key, "value", value, "default", defaultValue)
+		return defaultValue
+	}
+
+	return number
+}
+
+func getEnvBool(log *slog.Logger, key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Warn("Invalid boolean in environment, using default", "key", key, "value", value, "default", defaultValue)
+		return defaultValue
+	}
+
+	return flag
+}
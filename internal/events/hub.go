@@ -0,0 +1,137 @@
+package events
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	Channel = "subscription_event"
+
+	fetchLimit       = 500
+	subscriberBuffer = 64
+	// pollInterval bounds delay of events whose notification was lost while listener reconnected
+	pollInterval = 30 * time.Second
+)
+
+// Hub reads events from outbox whenever Postgres notifies about new ones and fans them out to subscribers,
+// so every instance of service sees changes made by any other instance
+type Hub struct {
+	repo     repository.OutboxRepositoryInterface
+	listener *pq.Listener
+	log      *slog.Logger
+
+	mu           sync.Mutex
+	subscribers  map[chan *models.Event]struct{}
+	lastSequence int64
+}
+
+func NewHub(repo repository.OutboxRepositoryInterface, listener *pq.Listener, log *slog.Logger) *Hub {
+	return &Hub{
+		repo:        repo,
+		listener:    listener,
+		log:         log,
+		subscribers: make(map[chan *models.Event]struct{}),
+	}
+}
+
+// Run dispatches events until ctx is cancelled
+func (h *Hub) Run(ctx context.Context) error {
+	lastSequence, err := h.repo.LastSequence(ctx)
+	if err != nil {
+		return err
+	}
+	h.lastSequence = lastSequence
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	// without listener events are still delivered, but only by polling
+	var notify <-chan *pq.Notification
+	if h.listener != nil {
+		notify = h.listener.Notify
+		defer h.listener.Close()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.closeSubscribers()
+			return nil
+		case <-notify:
+		case <-ticker.C:
+		}
+
+		h.dispatch(ctx)
+	}
+}
+
+// Subscribe returns channel receiving every subscription record change committed after the call. Channel is closed when
+// subscriber falls too far behind or hub stops, subscriber should then resume from the last received event
+func (h *Hub) Subscribe() (<-chan *models.Event, func()) {
+	ch := make(chan *models.Event, subscriberBuffer)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if _, exists := h.subscribers[ch]; exists {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+func (h *Hub) dispatch(ctx context.Context) {
+	for {
+		events, err := h.repo.ListSince(ctx, h.lastSequence, fetchLimit)
+		if err != nil {
+			h.log.Error("Failed to read events for subscribers", "error", err)
+			return
+		}
+
+		h.mu.Lock()
+		for _, event := range events {
+			h.lastSequence = event.Sequence
+			if !event.IsSubscriptionChange() {
+				continue
+			}
+
+			for ch := range h.subscribers {
+				select {
+				case ch <- event:
+				default:
+					delete(h.subscribers, ch)
+					close(ch)
+				}
+			}
+		}
+		h.mu.Unlock()
+
+		if len(events) < fetchLimit {
+			return
+		}
+	}
+}
+
+func (h *Hub) closeSubscribers() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
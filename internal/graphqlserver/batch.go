@@ -0,0 +1,48 @@
+package graphqlserver
+
+import (
+	"context"
+	"sync"
+)
+
+// siblingLoader loads a field of all sibling objects, e.g. items of one list, the first time any of them
+// resolves it, so the field costs one query per list instead of one per item. Siblings may resolve field
+// with different arguments, values are loaded once per distinct arguments
+type siblingLoader[A comparable, K comparable, V any] struct {
+	keys []K
+	load func(ctx context.Context, args A, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	batches map[A]*siblingBatch[K, V]
+}
+
+type siblingBatch[K comparable, V any] struct {
+	once   sync.Once
+	values map[K]V
+	err    error
+}
+
+func newSiblingLoader[A comparable, K comparable, V any](keys []K, load func(ctx context.Context, args A, keys []K) (map[K]V, error)) *siblingLoader[A, K, V] {
+	return &siblingLoader[A, K, V]{
+		keys:    keys,
+		load:    load,
+		batches: make(map[A]*siblingBatch[K, V]),
+	}
+}
+
+// get returns value of key loaded with args along with values of all siblings, zero value if there is none
+func (l *siblingLoader[A, K, V]) get(ctx context.Context, args A, key K) (V, error) {
+	l.mu.Lock()
+	batch, exists := l.batches[args]
+	if !exists {
+		batch = &siblingBatch[K, V]{}
+		l.batches[args] = batch
+	}
+	l.mu.Unlock()
+
+	batch.once.Do(func() {
+		batch.values, batch.err = l.load(ctx, args, l.keys)
+	})
+
+	return batch.values[key], batch.err
+}
@@ -0,0 +1,57 @@
+package graphqlserver
+
+import (
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+)
+
+const (
+	codeBadUserInput  = "BAD_USER_INPUT"
+	codeNotFound      = "NOT_FOUND"
+	codeConflict      = "CONFLICT"
+	codeUnprocessable = "UNPROCESSABLE"
+	codeTimeout       = "TIMEOUT"
+	codeInternal      = "INTERNAL"
+)
+
+// resolverError is error of resolver with code in extensions, so clients can tell invalid input
+// from missing records and failures the same way they do by HTTP status of REST API
+type resolverError struct {
+	message string
+	code    string
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+func (e *resolverError) Extensions() map[string]any {
+	return map[string]any{"code": e.code}
+}
+
+func badInput(err error) error {
+	return &resolverError{message: err.Error(), code: codeBadUserInput}
+}
+
+// handleError logs error and converts it to resolver error with code matching HTTP status REST API responds with
+func handleError(log *slog.Logger, message string, err error) error {
+	log.Error(message, "error", err, "api", "graphql")
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, repository.ErrSubscriptionNotFound):
+		return &resolverError{message: err.Error(), code: codeNotFound}
+	case errors.Is(err, repository.ErrOverlappingSubscription),
+		errors.Is(err, repository.ErrMergeConflict),
+		errors.Is(err, repository.ErrInvalidSplit):
+		return &resolverError{message: err.Error(), code: codeConflict}
+	case errors.Is(err, repository.ErrUnknownUser):
+		return &resolverError{message: err.Error(), code: codeUnprocessable}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &resolverError{message: message, code: codeTimeout}
+	default:
+		return &resolverError{message: message, code: codeInternal}
+	}
+}
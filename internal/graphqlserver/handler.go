@@ -0,0 +1,127 @@
+package graphqlserver
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSource string
+
+const DateFormatHeader = "X-Date-Format"
+
+// maxParallelism limits resolvers run concurrently within one request
+const maxParallelism = 20
+
+type dateFormatKey struct{}
+
+// Handler serves GraphQL API over the same repositories and models as REST handlers
+type Handler struct {
+	schema     *graphql.Schema
+	dateFormat models.DateFormat
+	log        *slog.Logger
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+func New(repo repository.RepositoryInterface, userRepo repository.UserRepositoryInterface, dateFormat models.DateFormat, log *slog.Logger) (*Handler, error) {
+	resolver := &Resolver{
+		repo:     repo,
+		userRepo: userRepo,
+		log:      log,
+	}
+
+	schema, err := graphql.ParseSchema(schemaSource, resolver, graphql.UseStringDescriptions(), graphql.MaxParallelism(maxParallelism))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse GraphQL schema: %v", err)
+	}
+
+	return &Handler{
+		schema:     schema,
+		dateFormat: dateFormat,
+		log:        log,
+	}, nil
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/graphql", h.ServeGraphQL).Methods("POST")
+}
+
+// @Summary GraphQL endpoint
+// @Description Executes GraphQL query or mutation over subscription records, users and costs. Schema is
+// @Description available through introspection. Fields of list items, like cost of every record, are
+// @Description loaded for the whole list at once
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param date_format query string false "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, server default if absent"
+// @Param X-Date-Format header string false "Format of dates in response, used if date_format query parameter is absent"
+// @Success 200 {object} object
+// @Router /graphql [post]
+func (h *Handler) ServeGraphQL(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("date_format")
+	if format == "" {
+		format = r.Header.Get(DateFormatHeader)
+	}
+
+	dateFormat, err := models.ParseDateFormat(format, h.dateFormat)
+	if err != nil {
+		h.handleError(w, "Invalid date format", err, http.StatusBadRequest)
+		return
+	}
+	ctx = context.WithValue(ctx, dateFormatKey{}, dateFormat)
+
+	response := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		h.handleError(w, "Failed to marshal response", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+
+	h.log.Info("GraphQL request executed", "operation", req.OperationName, "errors", len(response.Errors))
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, message string, err error, status int) {
+	http.Error(w, message, status)
+	h.log.Error(message, "error", err)
+}
+
+// dateFormatFrom returns format of dates in response to request of ctx
+func dateFormatFrom(ctx context.Context) models.DateFormat {
+	dateFormat, _ := ctx.Value(dateFormatKey{}).(models.DateFormat)
+	return dateFormat
+}
@@ -0,0 +1,436 @@
+package graphqlserver
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+)
+
+const maxPageSize = 100
+
+// Resolver is root resolver of queries and mutations
+type Resolver struct {
+	repo     repository.RepositoryInterface
+	userRepo repository.UserRepositoryInterface
+	log      *slog.Logger
+}
+
+type queryResolver struct {
+	*Resolver
+}
+
+type mutationResolver struct {
+	*Resolver
+}
+
+type subscriptionFilterInput struct {
+	UserID     *graphql.ID
+	AsOf       *string
+	Tags       *[]string
+	CostCenter *string
+}
+
+type memberInput struct {
+	UserID graphql.ID
+	Weight *int32
+}
+
+type subscriptionInput struct {
+	ServiceName string
+	Price       int32
+	UserID      graphql.ID
+	StartDate   string
+	EndDate     *string
+	BillingDay  *int32
+	Members     *[]memberInput
+	Tags        *[]string
+	CostCenter  *string
+	AutoRenew   *bool
+}
+
+type subscriptionPatch struct {
+	ServiceName *string
+	Price       *int32
+	UserID      *graphql.ID
+	StartDate   *string
+	EndDate     *string
+	BillingDay  *int32
+	Members     *[]memberInput
+	Tags        *[]string
+	CostCenter  *string
+	AutoRenew   *bool
+}
+
+type costResolver struct {
+	cost       int
+	categories []*models.SubscriptionCostItem
+}
+
+type costItemResolver struct {
+	item *models.SubscriptionCostItem
+}
+
+func (r *Resolver) Query() *queryResolver {
+	return &queryResolver{r}
+}
+
+func (r *Resolver) Mutation() *mutationResolver {
+	return &mutationResolver{r}
+}
+
+func (r *queryResolver) Subscription(ctx context.Context, args struct{ ID graphql.ID }) (*subscriptionResolver, error) {
+	id, err := parseSubscriptionID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := r.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, handleError(r.log, "Failed to get subscription record", err)
+	}
+
+	return r.newSubscriptionResolvers(ctx, []*models.Subscription{subscription}, nil)[0], nil
+}
+
+func (r *queryResolver) Subscriptions(ctx context.Context, args struct {
+	Filter *subscriptionFilterInput
+	First  int32
+	After  *graphql.ID
+}) (*subscriptionPageResolver, error) {
+	var filterRequest models.SubscriptionFilterRequest
+	var userID *uuid.UUID
+	if args.Filter != nil {
+		filterRequest.AsOf = deref(args.Filter.AsOf)
+		filterRequest.Tags = deref(args.Filter.Tags)
+		filterRequest.CostCenter = deref(args.Filter.CostCenter)
+
+		if args.Filter.UserID != nil {
+			id, err := uuid.Parse(string(*args.Filter.UserID))
+			if err != nil {
+				return nil, badInput(errors.New("Invalid userId format, must be uuid"))
+			}
+			userID = &id
+		}
+	}
+
+	filter, err := filterRequest.ToSubscriptionFilter()
+	if err != nil {
+		return nil, badInput(err)
+	}
+	filter.UserID = userID
+
+	first := int(args.First)
+	if first < 1 || first > maxPageSize {
+		return nil, badInput(errors.New("first must be from 1 to 100"))
+	}
+	filter.Limit = first + 1
+
+	if args.After != nil {
+		after, err := parseSubscriptionID(*args.After)
+		if err != nil {
+			return nil, err
+		}
+		filter.AfterID = after
+	}
+
+	subscriptions, err := r.repo.List(ctx, filter)
+	if err != nil {
+		return nil, handleError(r.log, "Failed to list subscription records", err)
+	}
+
+	page := &subscriptionPageResolver{hasNextPage: len(subscriptions) > first}
+	if page.hasNextPage {
+		subscriptions = subscriptions[:first]
+	}
+	page.items = r.newSubscriptionResolvers(ctx, subscriptions, filter.AsOf)
+
+	return page, nil
+}
+
+func (r *queryResolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	id, err := uuid.Parse(string(args.ID))
+	if err != nil {
+		return nil, badInput(errors.New("Invalid id format, must be uuid"))
+	}
+
+	user, err := r.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, nil
+		}
+		return nil, handleError(r.log, "Failed to get user", err)
+	}
+
+	return r.newUserResolvers([]*models.User{user})[0], nil
+}
+
+func (r *queryResolver) Users(ctx context.Context) ([]*userResolver, error) {
+	users, err := r.userRepo.List(ctx)
+	if err != nil {
+		return nil, handleError(r.log, "Failed to list users", err)
+	}
+
+	return r.newUserResolvers(users), nil
+}
+
+func (r *queryResolver) Cost(ctx context.Context, args struct {
+	StartDate   *string
+	EndDate     *string
+	UserID      *graphql.ID
+	ServiceName *string
+	Category    *string
+	AsOf        *string
+}) (*costResolver, error) {
+	subscriptionCost, err := parseCostRequest(models.SubscriptionCostRequest{
+		StartDate:   deref(args.StartDate),
+		EndDate:     deref(args.EndDate),
+		UserID:      string(deref(args.UserID)),
+		ServiceName: deref(args.ServiceName),
+		Category:    deref(args.Category),
+		AsOf:        deref(args.AsOf),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cost, err := r.repo.CalculateSubscriptionCost(ctx, subscriptionCost)
+	if err != nil {
+		return nil, handleError(r.log, "Failed to calculate subscription cost", err)
+	}
+
+	categories, err := r.repo.CalculateSubscriptionCostBreakdown(ctx, subscriptionCost, models.CostGroupByCategory)
+	if err != nil {
+		return nil, handleError(r.log, "Failed to calculate subscription cost per category", err)
+	}
+
+	return &costResolver{cost: cost, categories: categories}, nil
+}
+
+func (r *mutationResolver) CreateSubscription(ctx context.Context, args struct{ Input subscriptionInput }) (*subscriptionResolver, error) {
+	subscription, err := args.Input.toPatch().toRequest().ToSubscription()
+	if err != nil {
+		return nil, badInput(err)
+	}
+
+	if err := r.repo.Create(ctx, subscription); err != nil {
+		return nil, handleError(r.log, "Failed to create subscription record", err)
+	}
+
+	r.log.Info("Subscription record created successfully", "ID", subscription.ID, "api", "graphql")
+
+	return r.savedSubscriptionResolver(ctx, subscription), nil
+}
+
+func (r *mutationResolver) UpdateSubscription(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input subscriptionInput
+}) (*subscriptionResolver, error) {
+	id, err := parseSubscriptionID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := args.Input.toPatch().toRequest().ToSubscription()
+	if err != nil {
+		return nil, badInput(err)
+	}
+	subscription.ID = id
+
+	updated, err := r.repo.Update(ctx, subscription)
+	if err != nil {
+		return nil, handleError(r.log, "Failed to update subscription record", err)
+	}
+
+	r.log.Info("Subscription record updated successfully", "ID", id, "api", "graphql")
+
+	return r.savedSubscriptionResolver(ctx, updated), nil
+}
+
+func (r *mutationResolver) PatchSubscription(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input subscriptionPatch
+}) (*subscriptionResolver, error) {
+	id, err := parseSubscriptionID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := args.Input.toRequest()
+	subscription, err := req.ToSubscription()
+	if err != nil {
+		return nil, badInput(err)
+	}
+
+	old, err := r.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, handleError(r.log, "Failed to get subscription record", err)
+	}
+	subscription.Patch(old, req)
+
+	updated, err := r.repo.Update(ctx, subscription)
+	if err != nil {
+		return nil, handleError(r.log, "Failed to update subscription record", err)
+	}
+
+	r.log.Info("Subscription record patched successfully", "ID", id, "api", "graphql")
+
+	return r.savedSubscriptionResolver(ctx, updated), nil
+}
+
+func (r *mutationResolver) DeleteSubscription(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	id, err := parseSubscriptionID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.repo.DeleteByID(ctx, id); err != nil {
+		return false, handleError(r.log, "Failed to delete subscription record", err)
+	}
+
+	r.log.Info("Subscription record deleted successfully", "ID", id, "api", "graphql")
+
+	return true, nil
+}
+
+func (r *mutationResolver) MergeSubscriptions(ctx context.Context, args struct{ IDs []graphql.ID }) (*subscriptionResolver, error) {
+	var mergeRequest models.MergeRequest
+	for _, id := range args.IDs {
+		parsed, err := parseSubscriptionID(id)
+		if err != nil {
+			return nil, err
+		}
+		mergeRequest.IDs = append(mergeRequest.IDs, parsed)
+	}
+
+	if err := mergeRequest.Validate(); err != nil {
+		return nil, badInput(err)
+	}
+
+	merged, err := r.repo.Merge(ctx, mergeRequest.IDs)
+	if err != nil {
+		return nil, handleError(r.log, "Failed to merge subscription records", err)
+	}
+
+	r.log.Info("Subscription records merged successfully", "ids", mergeRequest.IDs, "id", merged.ID, "api", "graphql")
+
+	return r.newSubscriptionResolvers(ctx, []*models.Subscription{merged}, nil)[0], nil
+}
+
+func (r *mutationResolver) SplitSubscription(ctx context.Context, args struct {
+	ID    graphql.ID
+	Month string
+	Price *int32
+}) ([]*subscriptionResolver, error) {
+	id, err := parseSubscriptionID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	splitRequest := models.SplitRequest{Month: args.Month}
+	if args.Price != nil {
+		price := int(*args.Price)
+		splitRequest.Price = &price
+	}
+
+	from, err := splitRequest.ToDate()
+	if err != nil {
+		return nil, badInput(err)
+	}
+
+	parts, err := r.repo.Split(ctx, id, from, splitRequest.Price)
+	if err != nil {
+		return nil, handleError(r.log, "Failed to split subscription record", err)
+	}
+
+	r.log.Info("Subscription record split successfully", "id", id, "new_id", parts[1].ID, "api", "graphql")
+
+	return r.newSubscriptionResolvers(ctx, parts, nil), nil
+}
+
+// savedSubscriptionResolver makes resolver of created or updated record with warnings about budgets
+// it pushed over their limit
+func (r *Resolver) savedSubscriptionResolver(ctx context.Context, subscription *models.Subscription) *subscriptionResolver {
+	resolver := r.newSubscriptionResolvers(ctx, []*models.Subscription{subscription}, nil)[0]
+	resolver.response.Warnings = subscription.BudgetWarnings()
+
+	return resolver
+}
+
+func (c *costResolver) Cost() int32 {
+	return int32(c.cost)
+}
+
+func (c *costResolver) Categories() []*costItemResolver {
+	items := make([]*costItemResolver, 0, len(c.categories))
+	for _, item := range c.categories {
+		items = append(items, &costItemResolver{item: item})
+	}
+
+	return items
+}
+
+func (c *costItemResolver) Key() string {
+	return c.item.Key
+}
+
+func (c *costItemResolver) Cost() int32 {
+	return int32(c.item.Cost)
+}
+
+func (input subscriptionInput) toPatch() subscriptionPatch {
+	return subscriptionPatch{
+		ServiceName: &input.ServiceName,
+		Price:       &input.Price,
+		UserID:      &input.UserID,
+		StartDate:   &input.StartDate,
+		EndDate:     input.EndDate,
+		BillingDay:  input.BillingDay,
+		Members:     input.Members,
+		Tags:        input.Tags,
+		CostCenter:  input.CostCenter,
+		AutoRenew:   input.AutoRenew,
+	}
+}
+
+// toRequest maps input onto REST request, so both APIs share validation and parsing
+func (input subscriptionPatch) toRequest() models.SubscriptionRequest {
+	req := models.SubscriptionRequest{
+		ServiceName: deref(input.ServiceName),
+		Price:       int(deref(input.Price)),
+		UserID:      string(deref(input.UserID)),
+		StartDate:   deref(input.StartDate),
+		EndDate:     input.EndDate,
+		CostCenter:  input.CostCenter,
+		AutoRenew:   input.AutoRenew,
+	}
+
+	if input.BillingDay != nil {
+		billingDay := int(*input.BillingDay)
+		req.BillingDay = &billingDay
+	}
+
+	if input.Tags != nil {
+		req.Tags = *input.Tags
+	}
+
+	if input.Members != nil {
+		req.Members = make([]models.SubscriptionMemberRequest, 0, len(*input.Members))
+		for _, member := range *input.Members {
+			req.Members = append(req.Members, models.SubscriptionMemberRequest{
+				UserID: string(member.UserID),
+				Weight: int(deref(member.Weight)),
+			})
+		}
+	}
+
+	return req
+}
@@ -0,0 +1,305 @@
+package graphqlserver
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// period is period cost is resolved for, zero bound means the default one
+type period struct {
+	start time.Time
+	end   time.Time
+}
+
+type periodArgs struct {
+	StartDate *string
+	EndDate   *string
+}
+
+// subscriptionGroup holds loaders shared by subscription records resolved in one list
+type subscriptionGroup struct {
+	users *siblingLoader[struct{}, uuid.UUID, *userResolver]
+	costs *siblingLoader[period, int, int]
+}
+
+type subscriptionResolver struct {
+	subscription *models.Subscription
+	response     *models.SubscriptionResponse
+	group        *subscriptionGroup
+}
+
+type memberResolver struct {
+	member models.SubscriptionMemberResponse
+	group  *subscriptionGroup
+}
+
+type subscriptionPageResolver struct {
+	items       []*subscriptionResolver
+	hasNextPage bool
+}
+
+// newSubscriptionResolvers makes resolvers of subscription records listed as they stood at asOf, owners,
+// members and costs of all of them are loaded together
+func (r *Resolver) newSubscriptionResolvers(ctx context.Context, subscriptions []*models.Subscription, asOf *time.Time) []*subscriptionResolver {
+	ids := make([]int, 0, len(subscriptions))
+	var userIDs []uuid.UUID
+	seen := make(map[uuid.UUID]struct{})
+	addUser := func(id uuid.UUID) {
+		if _, exists := seen[id]; !exists {
+			seen[id] = struct{}{}
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	for _, subscription := range subscriptions {
+		ids = append(ids, subscription.ID)
+		addUser(subscription.UserID)
+		for _, member := range subscription.Members {
+			addUser(member.UserID)
+		}
+	}
+
+	group := &subscriptionGroup{
+		users: newSiblingLoader(userIDs, func(ctx context.Context, _ struct{}, keys []uuid.UUID) (map[uuid.UUID]*userResolver, error) {
+			users, err := r.userRepo.ListByIDs(ctx, keys)
+			if err != nil {
+				return nil, handleError(r.log, "Failed to load users", err)
+			}
+
+			resolvers := make(map[uuid.UUID]*userResolver, len(users))
+			for _, user := range r.newUserResolvers(users) {
+				resolvers[user.user.ID] = user
+			}
+
+			return resolvers, nil
+		}),
+		costs: newSiblingLoader(ids, func(ctx context.Context, p period, keys []int) (map[int]int, error) {
+			subscriptionCost := &models.SubscriptionCost{AsOf: asOf, IDs: keys}
+			if !p.start.IsZero() {
+				subscriptionCost.StartDate = &p.start
+			}
+			if !p.end.IsZero() {
+				subscriptionCost.EndDate = &p.end
+			}
+
+			items, err := r.repo.CalculateSubscriptionCostBreakdown(ctx, subscriptionCost, models.CostGroupBySubscription)
+			if err != nil {
+				return nil, handleError(r.log, "Failed to calculate subscription costs", err)
+			}
+
+			costs := make(map[int]int, len(items))
+			for _, item := range items {
+				id, err := strconv.Atoi(item.Key)
+				if err != nil {
+					return nil, handleError(r.log, "Failed to calculate subscription costs", err)
+				}
+				costs[id] = item.Cost
+			}
+
+			return costs, nil
+		}),
+	}
+
+	dateFormat := dateFormatFrom(ctx)
+	resolvers := make([]*subscriptionResolver, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		resolvers = append(resolvers, &subscriptionResolver{
+			subscription: subscription,
+			response:     subscription.ToResponseIn(dateFormat),
+			group:        group,
+		})
+	}
+
+	return resolvers
+}
+
+func (s *subscriptionResolver) ID() graphql.ID {
+	return graphql.ID(strconv.Itoa(s.subscription.ID))
+}
+
+func (s *subscriptionResolver) ServiceName() string {
+	return s.response.ServiceName
+}
+
+func (s *subscriptionResolver) Price() int32 {
+	return int32(s.response.Price)
+}
+
+func (s *subscriptionResolver) UserID() graphql.ID {
+	return graphql.ID(s.response.UserID)
+}
+
+func (s *subscriptionResolver) User(ctx context.Context) (*userResolver, error) {
+	return s.group.users.get(ctx, struct{}{}, s.subscription.UserID)
+}
+
+func (s *subscriptionResolver) StartDate() string {
+	return s.response.StartDate
+}
+
+func (s *subscriptionResolver) EndDate() *string {
+	return s.response.EndDate
+}
+
+func (s *subscriptionResolver) BillingDay() int32 {
+	return int32(s.response.BillingDay)
+}
+
+func (s *subscriptionResolver) Members() []*memberResolver {
+	members := make([]*memberResolver, 0, len(s.response.Members))
+	for _, member := range s.response.Members {
+		members = append(members, &memberResolver{member: member, group: s.group})
+	}
+
+	return members
+}
+
+func (s *subscriptionResolver) Tags() []string {
+	if s.response.Tags == nil {
+		return []string{}
+	}
+
+	return s.response.Tags
+}
+
+func (s *subscriptionResolver) CostCenter() *string {
+	return s.response.CostCenter
+}
+
+func (s *subscriptionResolver) Status() string {
+	return s.response.Status
+}
+
+func (s *subscriptionResolver) AutoRenew() bool {
+	return s.response.AutoRenew
+}
+
+func (s *subscriptionResolver) Cost(ctx context.Context, args periodArgs) (int32, error) {
+	p, err := args.toPeriod()
+	if err != nil {
+		return 0, err
+	}
+
+	cost, err := s.group.costs.get(ctx, p, s.subscription.ID)
+	return int32(cost), err
+}
+
+func (s *subscriptionResolver) Warnings() []string {
+	if s.response.Warnings == nil {
+		return []string{}
+	}
+
+	return s.response.Warnings
+}
+
+func (m *memberResolver) UserID() graphql.ID {
+	return graphql.ID(m.member.UserID)
+}
+
+func (m *memberResolver) User(ctx context.Context) (*userResolver, error) {
+	id, err := uuid.Parse(m.member.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.group.users.get(ctx, struct{}{}, id)
+}
+
+func (m *memberResolver) Weight() int32 {
+	return int32(m.member.Weight)
+}
+
+func (m *memberResolver) Share() float64 {
+	return m.member.Share
+}
+
+func (p *subscriptionPageResolver) Items() []*subscriptionResolver {
+	return p.items
+}
+
+func (p *subscriptionPageResolver) HasNextPage() bool {
+	return p.hasNextPage
+}
+
+func (p *subscriptionPageResolver) EndCursor() *graphql.ID {
+	if len(p.items) == 0 {
+		return nil
+	}
+
+	id := p.items[len(p.items)-1].ID()
+	return &id
+}
+
+func (p *subscriptionPageResolver) Cost(ctx context.Context, args periodArgs) (int32, error) {
+	var total int32
+	for _, item := range p.items {
+		cost, err := item.Cost(ctx, args)
+		if err != nil {
+			return 0, err
+		}
+		total += cost
+	}
+
+	return total, nil
+}
+
+// toPeriod parses period the same way cost of REST API does, month means its first day as start
+// and its last day as end
+func (args periodArgs) toPeriod() (period, error) {
+	subscriptionCost, err := parseCostRequest(models.SubscriptionCostRequest{
+		StartDate: deref(args.StartDate),
+		EndDate:   deref(args.EndDate),
+	})
+	if err != nil {
+		return period{}, err
+	}
+
+	var p period
+	if subscriptionCost.StartDate != nil {
+		p.start = *subscriptionCost.StartDate
+	}
+	if subscriptionCost.EndDate != nil {
+		p.end = *subscriptionCost.EndDate
+	}
+
+	return p, nil
+}
+
+// parseCostRequest parses cost filtering parameters, checking period ends after it starts
+func parseCostRequest(req models.SubscriptionCostRequest) (*models.SubscriptionCost, error) {
+	subscriptionCost, err := req.ToSubscriptionCost()
+	if err != nil {
+		return nil, badInput(err)
+	}
+
+	if subscriptionCost.StartDate != nil && subscriptionCost.EndDate != nil && subscriptionCost.EndDate.Before(*subscriptionCost.StartDate) {
+		return nil, badInput(errors.New("endDate must be bigger than startDate"))
+	}
+
+	return subscriptionCost, nil
+}
+
+func parseSubscriptionID(id graphql.ID) (int, error) {
+	parsed, err := strconv.Atoi(string(id))
+	if err != nil {
+		return 0, badInput(fmt.Errorf("Invalid id %q, must be integer", id))
+	}
+
+	return parsed, nil
+}
+
+func deref[T any](value *T) T {
+	var zero T
+	if value == nil {
+		return zero
+	}
+
+	return *value
+}
@@ -0,0 +1,171 @@
+package graphqlserver
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// userCostFilter is arguments user cost is resolved with
+type userCostFilter struct {
+	period      period
+	serviceName string
+	category    string
+}
+
+type userCostArgs struct {
+	StartDate   *string
+	EndDate     *string
+	ServiceName *string
+	Category    *string
+}
+
+// userGroup holds loaders shared by users resolved in one list
+type userGroup struct {
+	subscriptions *siblingLoader[struct{}, uuid.UUID, []*subscriptionResolver]
+	costs         *siblingLoader[userCostFilter, uuid.UUID, int]
+}
+
+type userResolver struct {
+	user  *models.User
+	group *userGroup
+}
+
+// newUserResolvers makes resolvers of users, subscription records and costs of all of them are loaded together
+func (r *Resolver) newUserResolvers(users []*models.User) []*userResolver {
+	ids := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	group := &userGroup{
+		subscriptions: newSiblingLoader(ids, func(ctx context.Context, _ struct{}, keys []uuid.UUID) (map[uuid.UUID][]*subscriptionResolver, error) {
+			subscriptions, err := r.repo.List(ctx, &models.SubscriptionFilter{UserIDs: keys})
+			if err != nil {
+				return nil, handleError(r.log, "Failed to list subscription records of users", err)
+			}
+
+			requested := make(map[uuid.UUID]struct{}, len(keys))
+			for _, key := range keys {
+				requested[key] = struct{}{}
+			}
+
+			byUser := make(map[uuid.UUID][]*subscriptionResolver, len(keys))
+			for _, subscription := range r.newSubscriptionResolvers(ctx, subscriptions, nil) {
+				owners := map[uuid.UUID]struct{}{subscription.subscription.UserID: {}}
+				for _, member := range subscription.subscription.Members {
+					owners[member.UserID] = struct{}{}
+				}
+
+				for id := range owners {
+					if _, exists := requested[id]; exists {
+						byUser[id] = append(byUser[id], subscription)
+					}
+				}
+			}
+
+			return byUser, nil
+		}),
+		costs: newSiblingLoader(ids, func(ctx context.Context, filter userCostFilter, keys []uuid.UUID) (map[uuid.UUID]int, error) {
+			subscriptionCost := &models.SubscriptionCost{}
+			if !filter.period.start.IsZero() {
+				subscriptionCost.StartDate = &filter.period.start
+			}
+			if !filter.period.end.IsZero() {
+				subscriptionCost.EndDate = &filter.period.end
+			}
+			if filter.serviceName != "" {
+				subscriptionCost.ServiceName.String = filter.serviceName
+				subscriptionCost.ServiceName.Valid = true
+			}
+			if filter.category != "" {
+				subscriptionCost.Category.String = filter.category
+				subscriptionCost.Category.Valid = true
+			}
+
+			items, err := r.repo.CalculateSubscriptionCostBreakdown(ctx, subscriptionCost, models.CostGroupByUser)
+			if err != nil {
+				return nil, handleError(r.log, "Failed to calculate user costs", err)
+			}
+
+			costs := make(map[uuid.UUID]int, len(keys))
+			for _, item := range items {
+				id, err := uuid.Parse(item.Key)
+				if err != nil {
+					return nil, handleError(r.log, "Failed to calculate user costs", err)
+				}
+				costs[id] = item.Cost
+			}
+
+			return costs, nil
+		}),
+	}
+
+	resolvers := make([]*userResolver, 0, len(users))
+	for _, user := range users {
+		resolvers = append(resolvers, &userResolver{user: user, group: group})
+	}
+
+	return resolvers
+}
+
+func (u *userResolver) ID() graphql.ID {
+	return graphql.ID(u.user.ID.String())
+}
+
+func (u *userResolver) Name() *string {
+	return u.user.Name
+}
+
+func (u *userResolver) Email() *string {
+	return u.user.Email
+}
+
+func (u *userResolver) CreatedAt() string {
+	return u.user.CreatedAt.Format(time.RFC3339)
+}
+
+func (u *userResolver) UpdatedAt() string {
+	return u.user.UpdatedAt.Format(time.RFC3339)
+}
+
+func (u *userResolver) Subscriptions(ctx context.Context) ([]*subscriptionResolver, error) {
+	subscriptions, err := u.group.subscriptions.get(ctx, struct{}{}, u.user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscriptions == nil {
+		return []*subscriptionResolver{}, nil
+	}
+
+	return subscriptions, nil
+}
+
+func (u *userResolver) Cost(ctx context.Context, args userCostArgs) (int32, error) {
+	subscriptionCost, err := parseCostRequest(models.SubscriptionCostRequest{
+		StartDate:   deref(args.StartDate),
+		EndDate:     deref(args.EndDate),
+		ServiceName: deref(args.ServiceName),
+		Category:    deref(args.Category),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var filter userCostFilter
+	if subscriptionCost.StartDate != nil {
+		filter.period.start = *subscriptionCost.StartDate
+	}
+	if subscriptionCost.EndDate != nil {
+		filter.period.end = *subscriptionCost.EndDate
+	}
+	filter.serviceName = subscriptionCost.ServiceName.String
+	filter.category = subscriptionCost.Category.String
+
+	cost, err := u.group.costs.get(ctx, filter, u.user.ID)
+	return int32(cost), err
+}
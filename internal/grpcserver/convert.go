@@ -0,0 +1,64 @@
+package grpcserver
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	subscriptionv1 "Effective-Mobile-Test/pkg/pb/subscription/v1"
+)
+
+// toSubscriptionRequest maps input onto REST request, so both APIs share validation and parsing
+func toSubscriptionRequest(input *subscriptionv1.SubscriptionInput) models.SubscriptionRequest {
+	req := models.SubscriptionRequest{
+		ServiceName: input.GetServiceName(),
+		Price:       int(input.GetPrice()),
+		UserID:      input.GetUserId(),
+		StartDate:   input.GetStartDate(),
+		EndDate:     input.EndDate,
+		Tags:        input.GetTags(),
+		CostCenter:  input.CostCenter,
+		AutoRenew:   input.AutoRenew,
+	}
+
+	if input.BillingDay != nil {
+		billingDay := int(input.GetBillingDay())
+		req.BillingDay = &billingDay
+	}
+
+	if input.Members != nil {
+		req.Members = make([]models.SubscriptionMemberRequest, 0, len(input.Members.GetItems()))
+		for _, member := range input.Members.GetItems() {
+			req.Members = append(req.Members, models.SubscriptionMemberRequest{
+				UserID: member.GetUserId(),
+				Weight: int(member.GetWeight()),
+			})
+		}
+	}
+
+	return req
+}
+
+func toProtoSubscription(resp *models.SubscriptionResponse) *subscriptionv1.Subscription {
+	subscription := &subscriptionv1.Subscription{
+		Id:          int64(resp.ID),
+		ServiceName: resp.ServiceName,
+		Price:       int64(resp.Price),
+		UserId:      resp.UserID,
+		StartDate:   resp.StartDate,
+		EndDate:     resp.EndDate,
+		BillingDay:  int32(resp.BillingDay),
+		Tags:        resp.Tags,
+		CostCenter:  resp.CostCenter,
+		Status:      resp.Status,
+		AutoRenew:   resp.AutoRenew,
+		Warnings:    resp.Warnings,
+	}
+
+	for _, member := range resp.Members {
+		subscription.Members = append(subscription.Members, &subscriptionv1.Member{
+			UserId: member.UserID,
+			Weight: int32(member.Weight),
+			Share:  member.Share,
+		})
+	}
+
+	return subscription
+}
@@ -0,0 +1,291 @@
+package grpcserver
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"Effective-Mobile-Test/internal/requestmeta"
+	subscriptionv1 "Effective-Mobile-Test/pkg/pb/subscription/v1"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	ActorMetadata      = "x-actor"
+	RequestIDMetadata  = "x-request-id"
+	DateFormatMetadata = "x-date-format"
+)
+
+// Server implements SubscriptionService on top of the same repositories and models as REST handlers
+type Server struct {
+	subscriptionv1.UnimplementedSubscriptionServiceServer
+
+	repo       repository.RepositoryInterface
+	dateFormat models.DateFormat
+	log        *slog.Logger
+}
+
+func New(repo repository.RepositoryInterface, dateFormat models.DateFormat, log *slog.Logger) *Server {
+	return &Server{
+		repo:       repo,
+		dateFormat: dateFormat,
+		log:        log,
+	}
+}
+
+// NewGRPCServer creates gRPC server serving subscription service with health checking and reflection
+func NewGRPCServer(server *Server) *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryRequestMeta),
+		grpc.ChainStreamInterceptor(streamRequestMeta),
+	)
+
+	subscriptionv1.RegisterSubscriptionServiceServer(grpcServer, server)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(subscriptionv1.SubscriptionService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	reflection.Register(grpcServer)
+
+	return grpcServer
+}
+
+func (s *Server) CreateSubscription(ctx context.Context, req *subscriptionv1.CreateSubscriptionRequest) (*subscriptionv1.Subscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	dateFormat, err := s.parseDateFormat(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := toSubscriptionRequest(req.GetSubscription()).ToSubscription()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.repo.Create(ctx, subscription); err != nil {
+		return nil, s.handleError("Failed to create subscription record", err)
+	}
+
+	s.log.Info("Subscription record created successfully", "ID", subscription.ID, "api", "grpc")
+
+	resp := subscription.ToResponseIn(dateFormat)
+	resp.Warnings = subscription.BudgetWarnings()
+
+	return toProtoSubscription(resp), nil
+}
+
+func (s *Server) GetSubscription(ctx context.Context, req *subscriptionv1.GetSubscriptionRequest) (*subscriptionv1.Subscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	dateFormat, err := s.parseDateFormat(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := s.repo.GetByID(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, s.handleError("Failed to get subscription record", err)
+	}
+
+	s.log.Info("Subscription record got successfully", "ID", subscription.ID, "api", "grpc")
+
+	return toProtoSubscription(subscription.ToResponseIn(dateFormat)), nil
+}
+
+func (s *Server) ListSubscriptions(req *subscriptionv1.ListSubscriptionsRequest, stream grpc.ServerStreamingServer[subscriptionv1.Subscription]) error {
+	ctx, cancel := context.WithTimeout(stream.Context(), 5*time.Minute)
+	defer cancel()
+
+	dateFormat, err := s.parseDateFormat(ctx)
+	if err != nil {
+		return err
+	}
+
+	filterRequest := models.SubscriptionFilterRequest{
+		AsOf:       req.GetAsOf(),
+		Tags:       req.GetTags(),
+		CostCenter: req.GetCostCenter(),
+	}
+
+	filter, err := filterRequest.ToSubscriptionFilter()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	amount := 0
+	err = s.repo.Stream(ctx, filter, func(subscription *models.Subscription) error {
+		amount++
+		return stream.Send(toProtoSubscription(subscription.ToResponseIn(dateFormat)))
+	})
+	if err != nil {
+		return s.handleError("Failed to list subscription records", err)
+	}
+
+	s.log.Info("Subscription records listed successfully", "amount", amount, "api", "grpc")
+
+	return nil
+}
+
+func (s *Server) UpdateSubscription(ctx context.Context, req *subscriptionv1.UpdateSubscriptionRequest) (*subscriptionv1.Subscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	dateFormat, err := s.parseDateFormat(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := toSubscriptionRequest(req.GetSubscription()).ToSubscription()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	subscription.ID = int(req.GetId())
+
+	updated, err := s.repo.Update(ctx, subscription)
+	if err != nil {
+		return nil, s.handleError("Failed to update subscription record", err)
+	}
+
+	s.log.Info("Subscription record updated successfully", "ID", updated.ID, "api", "grpc")
+
+	resp := updated.ToResponseIn(dateFormat)
+	resp.Warnings = updated.BudgetWarnings()
+
+	return toProtoSubscription(resp), nil
+}
+
+func (s *Server) DeleteSubscription(ctx context.Context, req *subscriptionv1.DeleteSubscriptionRequest) (*emptypb.Empty, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := s.repo.DeleteByID(ctx, int(req.GetId())); err != nil {
+		return nil, s.handleError("Failed to delete subscription record", err)
+	}
+
+	s.log.Info("Subscription record deleted successfully", "ID", req.GetId(), "api", "grpc")
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) CalculateCost(ctx context.Context, req *subscriptionv1.CalculateCostRequest) (*subscriptionv1.CalculateCostResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	costRequest := models.SubscriptionCostRequest{
+		StartDate:   req.GetStartDate(),
+		EndDate:     req.GetEndDate(),
+		UserID:      req.GetUserId(),
+		ServiceName: req.GetServiceName(),
+		AsOf:        req.GetAsOf(),
+		Category:    req.GetCategory(),
+	}
+
+	subscriptionCost, err := costRequest.ToSubscriptionCost()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if subscriptionCost.EndDate != nil && subscriptionCost.StartDate != nil && subscriptionCost.EndDate.Before(*subscriptionCost.StartDate) {
+		return nil, status.Error(codes.InvalidArgument, "end date must be bigger than start date")
+	}
+
+	cost, err := s.repo.CalculateSubscriptionCost(ctx, subscriptionCost)
+	if err != nil {
+		return nil, s.handleError("Failed to calculate subscription cost", err)
+	}
+
+	categories, err := s.repo.CalculateSubscriptionCostBreakdown(ctx, subscriptionCost, models.CostGroupByCategory)
+	if err != nil {
+		return nil, s.handleError("Failed to calculate subscription cost per category", err)
+	}
+
+	resp := &subscriptionv1.CalculateCostResponse{Cost: int64(cost)}
+	for _, category := range categories {
+		resp.Categories = append(resp.Categories, &subscriptionv1.CostItem{Key: category.Key, Cost: int64(category.Cost)})
+	}
+
+	s.log.Info("Subscription cost calculated successfully", "cost", cost, "api", "grpc")
+
+	return resp, nil
+}
+
+// parseDateFormat reads format of dates in response from metadata, falling back to the server default
+func (s *Server) parseDateFormat(ctx context.Context) (models.DateFormat, error) {
+	dateFormat, err := models.ParseDateFormat(firstMetadata(ctx, DateFormatMetadata), s.dateFormat)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return dateFormat, nil
+}
+
+// handleError logs error and converts it to status with code matching HTTP status REST API responds with
+func (s *Server) handleError(message string, err error) error {
+	s.log.Error(message, "error", err, "api", "grpc")
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, repository.ErrSubscriptionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, repository.ErrOverlappingSubscription):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, repository.ErrUnknownUser):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, message)
+	default:
+		return status.Error(codes.Internal, message)
+	}
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// withRequestMeta stores the actor and request ID of call in its context like REST middleware does
+func withRequestMeta(ctx context.Context) context.Context {
+	requestID := firstMetadata(ctx, RequestIDMetadata)
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+
+	ctx = requestmeta.WithRequestID(ctx, requestID)
+	return requestmeta.WithActor(ctx, firstMetadata(ctx, ActorMetadata))
+}
+
+func unaryRequestMeta(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withRequestMeta(ctx), req)
+}
+
+type requestMetaStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *requestMetaStream) Context() context.Context {
+	return s.ctx
+}
+
+func streamRequestMeta(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &requestMetaStream{ServerStream: stream, ctx: withRequestMeta(stream.Context())})
+}
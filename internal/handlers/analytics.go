@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type AnalyticsHandler struct {
+	repo repository.AnalyticsRepositoryInterface
+	log  *slog.Logger
+}
+
+func NewAnalyticsHandler(repo repository.AnalyticsRepositoryInterface, log *slog.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (h *AnalyticsHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/analytics/top-services", h.GetTopServices).Methods("GET")
+	router.HandleFunc("/analytics/spend-growth", h.GetSpendGrowth).Methods("GET")
+	router.HandleFunc("/analytics/churn", h.GetChurn).Methods("GET")
+	router.HandleFunc("/analytics/lifetime", h.GetLifetime).Methods("GET")
+}
+
+// @Summary Top services
+// @Description Ranks services by sum of monthly charges over the period or by number of subscribed users
+// @Tags analytics
+// @Produce json
+// @Param from query string false "First month of the period, format: MM-YYYY, 11 months before to by default"
+// @Param to query string false "Last month of the period, format: MM-YYYY, current month by default"
+// @Param by query string false "Ranking field: spend (default) or subscribers"
+// @Param limit query int false "Number of services, from 1 to 100, 10 by default"
+// @Success 200 {object} models.TopServicesResponse
+// @Router /analytics/top-services [get]
+func (h *AnalyticsHandler) GetTopServices(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	period, err := models.ParseAnalyticsPeriod(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	by, limit, err := models.ParseTopServices(r.URL.Query().Get("by"), r.URL.Query().Get("limit"))
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	services, err := h.repo.TopServices(ctx, period, by, limit)
+	if err != nil {
+		h.handleError(w, "Failed to rank services", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.TopServicesResponse{
+		From:     period.FromString(),
+		To:       period.ToString(),
+		By:       by,
+		Services: make([]models.ServiceSpendResponse, 0, len(services)),
+	}
+	for _, service := range services {
+		response.Services = append(response.Services, service.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Top services ranked successfully", "by", by, "amount", len(response.Services))
+}
+
+// @Summary Month-over-month spend growth
+// @Description Computes spend of every month of the period and its change relative to the previous month
+// @Tags analytics
+// @Produce json
+// @Param from query string false "First month of the period, format: MM-YYYY, 11 months before to by default"
+// @Param to query string false "Last month of the period, format: MM-YYYY, current month by default"
+// @Success 200 {object} models.SpendGrowthResponse
+// @Router /analytics/spend-growth [get]
+func (h *AnalyticsHandler) GetSpendGrowth(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	period, err := models.ParseAnalyticsPeriod(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	months, err := h.repo.SpendGrowth(ctx, period)
+	if err != nil {
+		h.handleError(w, "Failed to compute spend growth", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.SpendGrowthResponse{Months: make([]models.MonthlySpendResponse, 0, len(months))}
+	for _, month := range months {
+		response.Months = append(response.Months, month.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Spend growth computed successfully", "months", len(response.Months))
+}
+
+// @Summary Subscription churn
+// @Description Counts subscriptions active, started and ended in every month of the period. Records of the same
+// @Description user and service following each other without a gap are counted as one subscription
+// @Tags analytics
+// @Produce json
+// @Param from query string false "First month of the period, format: MM-YYYY, 11 months before to by default"
+// @Param to query string false "Last month of the period, format: MM-YYYY, current month by default"
+// @Success 200 {object} models.ChurnResponse
+// @Router /analytics/churn [get]
+func (h *AnalyticsHandler) GetChurn(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	period, err := models.ParseAnalyticsPeriod(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	months, err := h.repo.Churn(ctx, period)
+	if err != nil {
+		h.handleError(w, "Failed to compute churn", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.ChurnResponse{Months: make([]models.MonthlyChurnResponse, 0, len(months))}
+	for _, month := range months {
+		response.Months = append(response.Months, month.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Churn computed successfully", "months", len(response.Months))
+}
+
+// @Summary Average subscription lifetime
+// @Description Averages number of months subscriptions to every service last, active ones up to the current month
+// @Tags analytics
+// @Produce json
+// @Param service_name query string false "Service name for filtering"
+// @Success 200 {object} models.LifetimeResponse
+// @Router /analytics/lifetime [get]
+func (h *AnalyticsHandler) GetLifetime(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	services, err := h.repo.Lifetime(ctx, r.URL.Query().Get("service_name"))
+	if err != nil {
+		h.handleError(w, "Failed to compute subscription lifetime", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.LifetimeResponse{Services: make([]models.ServiceLifetimeResponse, 0, len(services))}
+	for _, service := range services {
+		response.Services = append(response.Services, service.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Subscription lifetime computed successfully", "services", len(response.Services))
+}
+
+func (h *AnalyticsHandler) handleError(w http.ResponseWriter, message string, err error, status int) {
+	http.Error(w, message, status)
+	h.log.Error(message, "error", err)
+}
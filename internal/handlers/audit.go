@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type AuditHandler struct {
+	repo repository.AuditRepositoryInterface
+	log  *slog.Logger
+}
+
+func NewAuditHandler(repo repository.AuditRepositoryInterface, log *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (h *AuditHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/subscriptions/{id:[0-9]+}/history", h.GetSubscriptionHistory).Methods("GET")
+	router.HandleFunc("/audit", h.QueryAuditLog).Methods("GET")
+}
+
+// @Summary Get change history of subscription record
+// @Description Returns every change of subscription record by ID in chronological order, including deletion
+// @Tags audit
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {array} models.AuditEntryResponse
+// @Router /subscriptions/{id}/history [get]
+func (h *AuditHandler) GetSubscriptionHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.handleError(w, "Invalid id in request", err, http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.repo.History(ctx, id)
+	if err != nil {
+		h.handleError(w, "Failed to get subscription record history", err, http.StatusInternalServerError)
+		return
+	}
+
+	h.writeEntries(w, entries)
+	h.log.Info("Subscription record history got successfully", "id", id, "amount", len(entries))
+}
+
+// @Summary Query audit log
+// @Description Returns audit log entries of all subscription records filtered by actor and time range
+// @Tags audit
+// @Produce json
+// @Param actor query string false "Actor for filtering"
+// @Param from query string false "Start of time range (RFC 3339)"
+// @Param to query string false "End of time range (RFC 3339)"
+// @Success 200 {array} models.AuditEntryResponse
+// @Router /audit [get]
+func (h *AuditHandler) QueryAuditLog(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	query := r.URL.Query()
+	filter, err := models.ParseAuditFilter(query.Get("actor"), query.Get("from"), query.Get("to"))
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.repo.Query(ctx, filter)
+	if err != nil {
+		h.handleError(w, "Failed to query audit log", err, http.StatusInternalServerError)
+		return
+	}
+
+	h.writeEntries(w, entries)
+	h.log.Info("Audit log queried successfully", "amount", len(entries))
+}
+
+func (h *AuditHandler) writeEntries(w http.ResponseWriter, entries []*models.AuditEntry) {
+	response := make([]*models.AuditEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		response = append(response, entry.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AuditHandler) handleError(w http.ResponseWriter, message string, err error, status int) {
+	http.Error(w, message, status)
+	h.log.Error(message, "error", err)
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type BudgetHandler struct {
+	repo repository.BudgetRepositoryInterface
+	log  *slog.Logger
+}
+
+func NewBudgetHandler(repo repository.BudgetRepositoryInterface, log *slog.Logger) *BudgetHandler {
+	return &BudgetHandler{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (h *BudgetHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/budgets", h.CreateBudget).Methods("POST")
+	router.HandleFunc("/budgets", h.ListBudgets).Methods("GET")
+	router.HandleFunc("/budgets/status", h.GetBudgetsStatus).Methods("GET")
+	router.HandleFunc("/budgets/alerts", h.ListBudgetAlerts).Methods("GET")
+
+	router.HandleFunc("/budgets/{id:[0-9]+}", h.GetBudget).Methods("GET")
+	router.HandleFunc("/budgets/{id:[0-9]+}", h.UpdateBudget).Methods("PUT")
+	router.HandleFunc("/budgets/{id:[0-9]+}", h.DeleteBudget).Methods("DELETE")
+}
+
+// @Summary Create budget
+// @Description Creates monthly budget scoped to user, service or user and service pair
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param budget body models.BudgetRequest true "Budget data"
+// @Success 201 {object} models.BudgetResponse
+// @Router /budgets [post]
+func (h *BudgetHandler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var budgetRequest models.BudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&budgetRequest); err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	budget, err := budgetRequest.ToBudget()
+	if err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.Create(ctx, budget); err != nil {
+		if errors.Is(err, repository.ErrUnknownUser) {
+			h.handleError(w, err.Error(), err, http.StatusUnprocessableEntity)
+			return
+		}
+		h.handleError(w, "Failed to create budget", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(budget.ToResponse())
+
+	h.log.Info("Budget created successfully", "id", budget.ID)
+}
+
+// @Summary Get budget by ID
+// @Description Gets budget by ID
+// @Tags budgets
+// @Produce json
+// @Param id path int true "Budget ID"
+// @Success 200 {object} models.BudgetResponse
+// @Router /budgets/{id} [get]
+func (h *BudgetHandler) GetBudget(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, "Invalid id in request", err, http.StatusBadRequest)
+		return
+	}
+
+	budget, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		h.handleError(w, "Failed to get budget", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budget.ToResponse())
+
+	h.log.Info("Budget got successfully", "id", id)
+}
+
+// @Summary List budgets
+// @Description Lists budgets
+// @Tags budgets
+// @Produce json
+// @Success 200 {array} models.BudgetResponse
+// @Router /budgets [get]
+func (h *BudgetHandler) ListBudgets(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	budgets, err := h.repo.List(ctx)
+	if err != nil {
+		h.handleError(w, "Failed to list budgets", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]*models.BudgetResponse, 0, len(budgets))
+	for _, budget := range budgets {
+		response = append(response, budget.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Budgets listed successfully", "amount", len(response))
+}
+
+// @Summary Update budget by ID
+// @Description Makes full update of budget by ID
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path int true "Budget ID"
+// @Param budget body models.BudgetRequest true "New data for budget"
+// @Success 200 {object} models.BudgetResponse
+// @Router /budgets/{id} [put]
+func (h *BudgetHandler) UpdateBudget(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, "Invalid id in request", err, http.StatusBadRequest)
+		return
+	}
+
+	var budgetRequest models.BudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&budgetRequest); err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	budget, err := budgetRequest.ToBudget()
+	if err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+	budget.ID = id
+
+	updatedBudget, err := h.repo.Update(ctx, budget)
+	if err != nil {
+		if errors.Is(err, repository.ErrUnknownUser) {
+			h.handleError(w, err.Error(), err, http.StatusUnprocessableEntity)
+			return
+		}
+		h.handleError(w, "Failed to update budget", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updatedBudget.ToResponse())
+
+	h.log.Info("Budget updated successfully", "id", id)
+}
+
+// @Summary Delete budget by ID
+// @Description Deletes budget by ID
+// @Tags budgets
+// @Param id path int true "Budget ID"
+// @Success 204 "No content"
+// @Router /budgets/{id} [delete]
+func (h *BudgetHandler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, "Invalid id in request", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.DeleteByID(ctx, id); err != nil {
+		h.handleError(w, "Failed to delete budget", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.log.Info("Budget deleted successfully", "id", id)
+}
+
+// @Summary Compare budgets against spend
+// @Description Compares every budget against actual spend of the month, computed like subscription cost of that month, and flags overruns
+// @Tags budgets
+// @Produce json
+// @Param month query string false "Month to compare (MM-YYYY), current month by default"
+// @Success 200 {array} models.BudgetStatusResponse
+// @Router /budgets/status [get]
+func (h *BudgetHandler) GetBudgetsStatus(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	month, err := models.ParseMonth(r.URL.Query().Get("month"))
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	statuses, err := h.repo.Status(ctx, month)
+	if err != nil {
+		h.handleError(w, "Failed to compare budgets", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]*models.BudgetStatusResponse, 0, len(statuses))
+	exceeded := 0
+	for _, status := range statuses {
+		response = append(response, status.ToResponse())
+		if status.Exceeded() {
+			exceeded++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Budgets compared successfully", "amount", len(response), "exceeded", exceeded)
+}
+
+// @Summary List budget alerts
+// @Description Lists the latest alerts raised when writes of subscription records pushed budgets over their limit, newest first.
+// @Description Budget is alerted once for every month it becomes exceeded in, not on every write while it stays exceeded.
+// @Description Write checks every month of the record period, up to the latest 12 of them, and each alert is also published as budget.exceeded outbox event
+// @Tags budgets
+// @Produce json
+// @Param budget_id query int false "Budget ID for filtering"
+// @Param limit query int false "Number of alerts, from 1 to 100, 20 by default"
+// @Success 200 {array} models.BudgetAlertResponse
+// @Router /budgets/alerts [get]
+func (h *BudgetHandler) ListBudgetAlerts(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	filter, err := models.ParseBudgetAlertFilter(r.URL.Query().Get("budget_id"), r.URL.Query().Get("limit"))
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	alerts, err := h.repo.ListAlerts(ctx, filter)
+	if err != nil {
+		h.handleError(w, "Failed to list budget alerts", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]*models.BudgetAlertResponse, 0, len(alerts))
+	for _, alert := range alerts {
+		response = append(response, alert.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Budget alerts listed successfully", "amount", len(response))
+}
+
+func (h *BudgetHandler) handleError(w http.ResponseWriter, message string, err error, status int) {
+	http.Error(w, message, status)
+	h.log.Error(message, "error", err)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/pkg/ical"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// @Summary Get calendar of upcoming subscription charges
+// @Description Returns RFC 5545 calendar with monthly recurring event on billing date of every active subscription of user
+// @Description and an event on end date of subscriptions that end
+// @Tags subscriptions
+// @Produce text/calendar
+// @Param user_id path string true "User UUID"
+// @Success 200 {string} string "iCalendar feed"
+// @Router /users/{user_id}/charges.ics [get]
+func (h *SubscriptionHandler) GetUserChargesCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["user_id"])
+	if err != nil {
+		h.handleError(w, "Invalid user_id format, must be uuid", err, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	subscriptions, err := h.repo.List(ctx, &models.SubscriptionFilter{UserID: &userID, NotEndedBefore: &currentMonth})
+	if err != nil {
+		h.handleError(w, "Failed to list subsription records", err, http.StatusInternalServerError)
+		return
+	}
+
+	calendar := ical.Calendar{
+		ProdID: "-//Effective-Mobile-Test//Subscription charges//EN",
+		Name:   "Subscription charges",
+	}
+
+	for _, subscription := range subscriptions {
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:         fmt.Sprintf("subscription-%d-charge@effective-mobile-test", subscription.ID),
+			Summary:     fmt.Sprintf("%s: %d RUB", subscription.ServiceName, subscription.Price),
+			Description: fmt.Sprintf("Monthly charge for %s subscription", subscription.ServiceName),
+			Date:        subscription.FirstChargeDate(),
+			RRule:       ical.MonthlyOnDayUntil(subscription.BillingDay, subscription.EndDate),
+		})
+
+		if subscription.EndDate != nil {
+			calendar.Events = append(calendar.Events, ical.Event{
+				UID:         fmt.Sprintf("subscription-%d-end@effective-mobile-test", subscription.ID),
+				Summary:     fmt.Sprintf("%s subscription ends", subscription.ServiceName),
+				Description: fmt.Sprintf("Last day of %s subscription", subscription.ServiceName),
+				Date:        *subscription.EndDate,
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="charges.ics"`)
+	if _, err := calendar.WriteTo(w); err != nil {
+		h.log.Error("Failed to write calendar", "error", err)
+		return
+	}
+
+	h.log.Info("Subscription charges calendar got successfully", "user_id", userID, "subscriptions", len(subscriptions))
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/events"
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	LastEventIDHeader = "Last-Event-ID"
+
+	heartbeatInterval = 15 * time.Second
+	replayLimit       = 500
+)
+
+type EventsHandler struct {
+	hub  *events.Hub
+	repo repository.OutboxRepositoryInterface
+	log  *slog.Logger
+}
+
+func NewEventsHandler(hub *events.Hub, repo repository.OutboxRepositoryInterface, log *slog.Logger) *EventsHandler {
+	return &EventsHandler{
+		hub:  hub,
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (h *EventsHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/subscriptions/events", h.StreamSubscriptionEvents).Methods("GET")
+}
+
+// @Summary Stream subscription changes
+// @Description Server-Sent Events stream of subscription record changes. Event id is outbox sequence number,
+// @Description reconnecting client passing it in Last-Event-ID header (or last_event_id parameter) receives every missed event
+// @Tags subscriptions
+// @Produce text/event-stream
+// @Param user_id query string false "User UUID for filtering"
+// @Param service_name query string false "Service name for filtering"
+// @Param last_event_id query int false "Sequence number to resume after, used when Last-Event-ID header is absent"
+// @Success 200 {string} string "Event stream"
+// @Router /subscriptions/events [get]
+func (h *EventsHandler) StreamSubscriptionEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.handleError(w, "Streaming is not supported", errors.New("response writer is not a flusher"), http.StatusInternalServerError)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID != "" {
+		if _, err := uuid.Parse(userID); err != nil {
+			h.handleError(w, "Invalid user_id format, must be uuid", err, http.StatusBadRequest)
+			return
+		}
+	}
+	serviceName := r.URL.Query().Get("service_name")
+
+	lastEventID := r.Header.Get(LastEventIDHeader)
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+
+	var lastSequence int64 = -1
+	if lastEventID != "" {
+		sequence, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || sequence < 0 {
+			h.handleError(w, "Invalid last event id", err, http.StatusBadRequest)
+			return
+		}
+		lastSequence = sequence
+	}
+
+	// subscribe before replaying, so events committed during replay are not lost
+	live, unsubscribe := h.hub.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(event *models.Event) error {
+		lastSequence = event.Sequence
+		if !event.Matches(userID, serviceName) {
+			return nil
+		}
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+
+		return nil
+	}
+
+	for lastSequence >= 0 {
+		missed, err := h.repo.ListSince(ctx, lastSequence, replayLimit)
+		if err != nil {
+			h.log.Error("Failed to replay subscription events", "error", err)
+			return
+		}
+
+		for _, event := range missed {
+			if err := send(event); err != nil {
+				return
+			}
+		}
+
+		if len(missed) < replayLimit {
+			break
+		}
+	}
+
+	h.log.Info("Subscription events stream opened", "user_id", userID, "service_name", serviceName, "last_event_id", lastEventID)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-live:
+			if !ok {
+				// subscriber fell behind or server is stopping, client reconnects with Last-Event-ID
+				return
+			}
+			if event.Sequence <= lastSequence {
+				continue
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *EventsHandler) handleError(w http.ResponseWriter, message string, err error, status int) {
+	http.Error(w, message, status)
+	h.log.Error(message, "error", err)
+}
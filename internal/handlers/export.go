@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/pkg/export"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var subscriptionExportHeader = []string{"id", "service_name", "price", "user_id", "start_date", "end_date", "billing_day", "tags", "cost_center"}
+
+// exportSubscriptionRecords streams subscription records from database cursor straight into response.
+// Once the first row is written status can not be changed, so later failures are only logged
+func (h *SubscriptionHandler) exportSubscriptionRecords(w http.ResponseWriter, r *http.Request, format string, filter *models.SubscriptionFilter) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	writer, err := export.NewWriter(w, format, "subscriptions", subscriptionExportHeader)
+	if err != nil {
+		h.handleError(w, "Failed to start export", err, http.StatusInternalServerError)
+		return
+	}
+
+	amount := 0
+	err = h.repo.Stream(ctx, filter, func(subscription *models.Subscription) error {
+		amount++
+		return writer.WriteRow(subscriptionExportRow(subscription.ToResponseIn(filter.DateFormat)))
+	})
+	if err != nil {
+		writer.Abort()
+		h.log.Error("Failed to export subscription records", "error", err, "format", format, "exported", amount)
+		return
+	}
+
+	if err := writer.Close(); err != nil {
+		h.log.Error("Failed to finish export of subscription records", "error", err, "format", format)
+		return
+	}
+
+	h.log.Info("Subscription records exported successfully", "format", format, "amount", amount)
+}
+
+func (h *SubscriptionHandler) exportSubscriptionCostBreakdown(w http.ResponseWriter, format, groupBy string, items []*models.SubscriptionCostItem) {
+	writer, err := export.NewWriter(w, format, "cost-breakdown", []string{groupBy, "cost"})
+	if err != nil {
+		h.handleError(w, "Failed to start export", err, http.StatusInternalServerError)
+		return
+	}
+
+	for _, item := range items {
+		if err := writer.WriteRow([]any{item.Key, item.Cost}); err != nil {
+			writer.Abort()
+			h.log.Error("Failed to export subscription cost breakdown", "error", err, "format", format)
+			return
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		h.log.Error("Failed to finish export of subscription cost breakdown", "error", err, "format", format)
+		return
+	}
+
+	h.log.Info("Subscription cost breakdown exported successfully", "format", format, "groups", len(items))
+}
+
+func subscriptionExportRow(subscription *models.SubscriptionResponse) []any {
+	var endDate any
+	if subscription.EndDate != nil {
+		endDate = *subscription.EndDate
+	}
+
+	var costCenter any
+	if subscription.CostCenter != nil {
+		costCenter = *subscription.CostCenter
+	}
+
+	return []any{
+		subscription.ID,
+		subscription.ServiceName,
+		subscription.Price,
+		subscription.UserID,
+		subscription.StartDate,
+		endDate,
+		subscription.BillingDay,
+		strings.Join(subscription.Tags, ";"),
+		costCenter,
+	}
+}
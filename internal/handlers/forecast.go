@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// @Summary Forecast subscription spend
+// @Description Projects month-by-month cost of the next N months from active subscriptions, their known end dates
+// @Description and records starting in the future, e.g. scheduled price changes, broken down by service
+// @Tags subscriptions
+// @Produce json
+// @Param months query int false "Number of months to forecast, from 1 to 60, 12 by default"
+// @Success 200 {object} models.ForecastResponse
+// @Router /subscriptions/forecast [get]
+func (h *SubscriptionHandler) ForecastSubscriptionCost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	months, err := models.ParseForecastMonths(r.URL.Query().Get("months"))
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	currentMonth, _ := models.ParseMonth("")
+	forecast, err := h.repo.Forecast(ctx, currentMonth.AddDate(0, 1, 0), months)
+	if err != nil {
+		h.handleError(w, "Failed to forecast subscription cost", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.ForecastResponse{Months: make([]models.ForecastMonthResponse, 0, len(forecast))}
+	for _, month := range forecast {
+		response.Months = append(response.Months, month.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Subscription cost forecasted successfully", "months", months)
+}
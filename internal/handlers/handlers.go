@@ -3,6 +3,7 @@ package handlers
 import (
 	"Effective-Mobile-Test/internal/models"
 	"Effective-Mobile-Test/internal/repository"
+	"Effective-Mobile-Test/pkg/export"
 	"context"
 	"encoding/json"
 	"errors"
@@ -13,31 +14,48 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
+const maxBatchSize = 10000
+
+// maxBatchBodySize limits body of batch request, so oversized batch is rejected before it is read
+// into memory. It leaves about 4 KiB per record of the largest allowed batch
+const maxBatchBodySize = maxBatchSize * 4 << 10
+
 type SubscriptionHandler struct {
-	repo repository.RepositoryInterface
-	log  *slog.Logger
+	repo       repository.RepositoryInterface
+	dateFormat models.DateFormat
+	log        *slog.Logger
 }
 
-func NewSubscriptionHandler(repo repository.RepositoryInterface, log *slog.Logger) *SubscriptionHandler {
+func NewSubscriptionHandler(repo repository.RepositoryInterface, dateFormat models.DateFormat, log *slog.Logger) *SubscriptionHandler {
 	return &SubscriptionHandler{
-		repo: repo,
-		log:  log,
+		repo:       repo,
+		dateFormat: dateFormat,
+		log:        log,
 	}
 }
 
 func (h *SubscriptionHandler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/subscriptions", h.CreateSubscriptionRecord).Methods("POST")
+	router.HandleFunc("/subscriptions/batch", h.CreateSubscriptionRecordsBatch).Methods("POST")
+	router.HandleFunc("/subscriptions/import", h.ImportSubscriptionRecords).Methods("POST")
 	router.HandleFunc("/subscriptions", h.ListSubsriptionRecords).Methods("GET")
 	router.HandleFunc("/subscriptions/total-cost", h.CalculateSubscriptionCost).Methods("GET")
+	router.HandleFunc("/subscriptions/cost-breakdown", h.CalculateSubscriptionCostBreakdown).Methods("GET")
+	router.HandleFunc("/subscriptions/forecast", h.ForecastSubscriptionCost).Methods("GET")
+	router.HandleFunc("/subscriptions/overlaps", h.ListOverlappingSubscriptionRecords).Methods("GET")
+	router.HandleFunc("/subscriptions/merge", h.MergeSubscriptionRecords).Methods("POST")
 
 	router.HandleFunc("/subscriptions/{id:[0-9]+}", h.GetSubscriptionRecord).Methods("GET")
 	router.HandleFunc("/subscriptions/{id:[0-9]+}", h.UpdateSubscriptionRecord).Methods("PUT")
 	router.HandleFunc("/subscriptions/{id:[0-9]+}", h.PatchSubscriptionRecord).Methods("PATCH")
 	router.HandleFunc("/subscriptions/{id:[0-9]+}", h.DeleteSubscriptionRecord).Methods("DELETE")
+	router.HandleFunc("/subscriptions/{id:[0-9]+}/split", h.SplitSubscriptionRecord).Methods("POST")
+
+	router.HandleFunc("/users/{user_id}/charges.ics", h.GetUserChargesCalendar).Methods("GET")
+	router.HandleFunc("/users/{user_id}/summary", h.GetUserSummary).Methods("GET")
 }
 
 // @Summary Create new subscription record
@@ -45,7 +63,10 @@ func (h *SubscriptionHandler) RegisterRoutes(router *mux.Router) {
 // @Tags subscriptions
 // @Accept json
 // @Produce json
+// @Param date_format query string false "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise"
+// @Param X-Date-Format header string false "Format of dates in response, used if date_format query parameter is absent"
 // @Success 201 {object} models.SubscriptionResponse
+// @Failure 409 {string} string "Record overlaps another record of the same user and service in strict overlap mode"
 // @Router /subscriptions [post]
 func (h *SubscriptionHandler) CreateSubscriptionRecord(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
@@ -58,6 +79,11 @@ func (h *SubscriptionHandler) CreateSubscriptionRecord(w http.ResponseWriter, r
 
 	defer r.Body.Close()
 
+	dateFormat, ok := h.parseDateFormat(w, r)
+	if !ok {
+		return
+	}
+
 	var createSubscription models.SubscriptionRequest
 
 	body, err := io.ReadAll(r.Body)
@@ -80,6 +106,14 @@ func (h *SubscriptionHandler) CreateSubscriptionRecord(w http.ResponseWriter, r
 
 	err = h.repo.Create(ctx, subscription)
 	if err != nil {
+		if errors.Is(err, repository.ErrOverlappingSubscription) {
+			h.handleError(w, err.Error(), err, http.StatusConflict)
+			return
+		}
+		if errors.Is(err, repository.ErrUnknownUser) {
+			h.handleError(w, err.Error(), err, http.StatusUnprocessableEntity)
+			return
+		}
 		h.handleError(w, "Failed to create subscription record", err, http.StatusInternalServerError)
 		return
 	}
@@ -89,7 +123,8 @@ func (h *SubscriptionHandler) CreateSubscriptionRecord(w http.ResponseWriter, r
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 
-	subscriptionResponse := subscription.ToResponse()
+	subscriptionResponse := subscription.ToResponseIn(dateFormat)
+	subscriptionResponse.Warnings = h.budgetWarnings(subscription)
 
 	data, err := json.Marshal(subscriptionResponse)
 	if err != nil {
@@ -99,11 +134,129 @@ func (h *SubscriptionHandler) CreateSubscriptionRecord(w http.ResponseWriter, r
 	w.Write(data)
 }
 
+// @Summary Create subscription records in batch
+// @Description Creates many subscription records at once. In atomic mode nothing is created if any record fails, in partial mode every record is created independently
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param mode query string false "Batch mode: atomic (default) or partial"
+// @Param subscriptions body []models.SubscriptionRequest true "Subscription records to create"
+// @Success 201 {object} models.BatchResponse
+// @Success 207 {object} models.BatchResponse
+// @Failure 422 {object} models.BatchResponse
+// @Failure 413 {string} string "Batch has more records than allowed or its body is too large"
+// @Router /subscriptions/batch [post]
+func (h *SubscriptionHandler) CreateSubscriptionRecordsBatch(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	defer r.Body.Close()
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = models.BatchModeAtomic
+	}
+	if mode != models.BatchModeAtomic && mode != models.BatchModePartial {
+		h.handleError(w, "Invalid mode, must be atomic or partial", fmt.Errorf("unknown batch mode %q", mode), http.StatusBadRequest)
+		return
+	}
+
+	var batchRequest []models.SubscriptionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodySize)).Decode(&batchRequest); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.handleError(w, fmt.Sprintf("Batch body must be at most %d bytes", maxBatchBodySize), err, http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if len(batchRequest) == 0 {
+		h.handleError(w, "Batch must contain at least one subscription record", errors.New("empty batch"), http.StatusBadRequest)
+		return
+	}
+
+	if len(batchRequest) > maxBatchSize {
+		h.handleError(w, fmt.Sprintf("Batch must contain at most %d subscription records", maxBatchSize), errors.New("batch too large"), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	response := models.BatchResponse{
+		Mode:  mode,
+		Items: make([]models.BatchItemResponse, len(batchRequest)),
+	}
+
+	var valid []*models.Subscription
+	var validIndexes []int
+	for i, item := range batchRequest {
+		response.Items[i].Index = i
+
+		subscription, err := item.ToSubscription()
+		if err != nil {
+			response.Items[i].Error = err.Error()
+			continue
+		}
+
+		valid = append(valid, subscription)
+		validIndexes = append(validIndexes, i)
+	}
+
+	invalid := len(batchRequest) - len(valid)
+	if mode == models.BatchModeAtomic && invalid > 0 {
+		for _, i := range validIndexes {
+			response.Items[i].Error = repository.ErrBatchRolledBack.Error()
+		}
+	} else if len(valid) > 0 {
+		errs := h.repo.CreateBatch(ctx, valid, mode == models.BatchModeAtomic)
+		for j, err := range errs {
+			i := validIndexes[j]
+			if err != nil {
+				response.Items[i].Error = err.Error()
+				continue
+			}
+
+			id := valid[j].ID
+			response.Items[i].ID = &id
+		}
+	}
+
+	for _, item := range response.Items {
+		if item.Error == "" {
+			response.Created++
+		} else {
+			response.Failed++
+		}
+	}
+
+	status := http.StatusCreated
+	if response.Failed > 0 {
+		if mode == models.BatchModeAtomic {
+			status = http.StatusUnprocessableEntity
+		} else {
+			status = http.StatusMultiStatus
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Subscription records batch processed", "mode", mode, "created", response.Created, "failed", response.Failed)
+}
+
 // @Summary Get subscription record by ID
 // @Description Gets subscription record by ID
 // @Tags subscriptions
 // @Produce json
 // @Param id path int true "Subscription ID"
+// @Param date_format query string false "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise"
+// @Param X-Date-Format header string false "Format of dates in response, used if date_format query parameter is absent"
 // @Success 200 {object} models.SubscriptionResponse
 // @Router /subscriptions/{id} [get]
 func (h *SubscriptionHandler) GetSubscriptionRecord(w http.ResponseWriter, r *http.Request) {
@@ -120,6 +273,11 @@ func (h *SubscriptionHandler) GetSubscriptionRecord(w http.ResponseWriter, r *ht
 		return
 	}
 
+	dateFormat, ok := h.parseDateFormat(w, r)
+	if !ok {
+		return
+	}
+
 	subscription, err := h.repo.GetByID(ctx, id)
 	if err != nil {
 		h.handleError(w, fmt.Sprintf("Failed to get subscription record with id: %d", id), err, http.StatusInternalServerError)
@@ -127,7 +285,7 @@ func (h *SubscriptionHandler) GetSubscriptionRecord(w http.ResponseWriter, r *ht
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	subscriptionResponse := subscription.ToResponse()
+	subscriptionResponse := subscription.ToResponseIn(dateFormat)
 	data, err := json.Marshal(subscriptionResponse)
 	if err != nil {
 		h.handleError(w, "Failed to marshal response", err, http.StatusInternalServerError)
@@ -138,18 +296,57 @@ func (h *SubscriptionHandler) GetSubscriptionRecord(w http.ResponseWriter, r *ht
 }
 
 // @Summary List subscription records
-// @Description Lists subscription records
+// @Description Lists subscription records. Besides JSON, records can be exported as CSV, NDJSON or XLSX
+// @Description chosen by format query parameter or Accept header, such exports are streamed row by row
 // @Tags subscriptions
 // @Produce json
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param as_of query string false "Moment to list subscription records as they stood at (RFC 3339)"
+// @Param tag query []string false "Tag listed records must be marked with, repeat to require several tags" collectionFormat(multi)
+// @Param cost_center query string false "Cost center of listed records"
+// @Param format query string false "Response format: json (default), csv, ndjson or xlsx"
+// @Param date_format query string false "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise"
+// @Param X-Date-Format header string false "Format of dates in response, used if date_format query parameter is absent"
 // @Success 200 {array} models.SubscriptionResponse
 // @Router /subscriptions [get]
 func (h *SubscriptionHandler) ListSubsriptionRecords(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	format, err := export.NegotiateFormat(r)
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	subscriptionFilterRequest := models.SubscriptionFilterRequest{
+		AsOf:       r.URL.Query().Get("as_of"),
+		Tags:       r.URL.Query()["tag"],
+		CostCenter: r.URL.Query().Get("cost_center"),
+	}
+
+	subscriptionFilter, err := subscriptionFilterRequest.ToSubscriptionFilter()
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	dateFormat, ok := h.parseDateFormat(w, r)
+	if !ok {
+		return
+	}
+	subscriptionFilter.DateFormat = dateFormat
+
+	if format != export.FormatJSON {
+		h.exportSubscriptionRecords(w, r, format, subscriptionFilter)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	defer r.Body.Close()
-
-	subscriptions, err := h.repo.List(ctx)
+	subscriptions, err := h.repo.List(ctx, subscriptionFilter)
 	if err != nil {
 		h.handleError(w, "Failed to list subsription records", err, http.StatusInternalServerError)
 		return
@@ -157,7 +354,7 @@ func (h *SubscriptionHandler) ListSubsriptionRecords(w http.ResponseWriter, r *h
 
 	var response []*models.SubscriptionResponse
 	for _, sub := range subscriptions {
-		curr := sub.ToResponse()
+		curr := sub.ToResponseIn(subscriptionFilter.DateFormat)
 		response = append(response, curr)
 	}
 
@@ -173,7 +370,10 @@ func (h *SubscriptionHandler) ListSubsriptionRecords(w http.ResponseWriter, r *h
 // @Produce json
 // @Param id path int true "Subscription ID"
 // @Param subscription body models.SubscriptionRequest true "New data for subscription record"
+// @Param date_format query string false "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise"
+// @Param X-Date-Format header string false "Format of dates in response, used if date_format query parameter is absent"
 // @Success 200 {object} models.SubscriptionResponse
+// @Failure 409 {string} string "Record overlaps another record of the same user and service in strict overlap mode"
 // @Router /subscriptions/{id} [put]
 func (h *SubscriptionHandler) UpdateSubscriptionRecord(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
@@ -214,14 +414,28 @@ func (h *SubscriptionHandler) UpdateSubscriptionRecord(w http.ResponseWriter, r
 	}
 	subscription.ID = id
 
+	dateFormat, ok := h.parseDateFormat(w, r)
+	if !ok {
+		return
+	}
+
 	updatedSubscription, err := h.repo.Update(ctx, subscription)
 	if err != nil {
+		if errors.Is(err, repository.ErrOverlappingSubscription) {
+			h.handleError(w, err.Error(), err, http.StatusConflict)
+			return
+		}
+		if errors.Is(err, repository.ErrUnknownUser) {
+			h.handleError(w, err.Error(), err, http.StatusUnprocessableEntity)
+			return
+		}
 		h.handleError(w, "Failed to update subscription record", err, http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	subscriptionResponse := updatedSubscription.ToResponse()
+	subscriptionResponse := updatedSubscription.ToResponseIn(dateFormat)
+	subscriptionResponse.Warnings = h.budgetWarnings(updatedSubscription)
 	data, err := json.Marshal(subscriptionResponse)
 	if err != nil {
 		h.handleError(w, "Failed to marshal updated subscription record", err, http.StatusInternalServerError)
@@ -239,7 +453,10 @@ func (h *SubscriptionHandler) UpdateSubscriptionRecord(w http.ResponseWriter, r
 // @Produce json
 // @Param id path int true "Subscription ID"
 // @Param subscription body models.SubscriptionRequest true "Data for partial updating subscription record"
+// @Param date_format query string false "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise"
+// @Param X-Date-Format header string false "Format of dates in response, used if date_format query parameter is absent"
 // @Success 200 {object} models.SubscriptionResponse
+// @Failure 409 {string} string "Record overlaps another record of the same user and service in strict overlap mode"
 // @Router /subscriptions/{id} [patch]
 func (h *SubscriptionHandler) PatchSubscriptionRecord(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
@@ -278,43 +495,37 @@ func (h *SubscriptionHandler) PatchSubscriptionRecord(w http.ResponseWriter, r *
 		return
 	}
 
+	dateFormat, ok := h.parseDateFormat(w, r)
+	if !ok {
+		return
+	}
+
 	oldSubscription, err := h.repo.GetByID(ctx, id)
 	if err != nil {
 		h.handleError(w, "Failed to get subscription record by id", err, http.StatusInternalServerError)
 		return
 	}
 
-	if newSubscription.ServiceName == "" {
-		newSubscription.ServiceName = oldSubscription.ServiceName
-	}
-
-	if newSubscription.Price == 0 {
-		newSubscription.Price = oldSubscription.Price
-	}
-
-	if newSubscription.UserID == uuid.Nil {
-		newSubscription.UserID = oldSubscription.UserID
-	}
-
-	if newSubscription.StartDate.IsZero() {
-		newSubscription.StartDate = oldSubscription.StartDate
-	}
-
-	if newSubscription.EndDate == nil {
-		newSubscription.EndDate = oldSubscription.EndDate
-	}
-
-	newSubscription.ID = id
+	newSubscription.Patch(oldSubscription, newSubscriptionRequest)
 
 	updatedSubsription, err := h.repo.Update(ctx, newSubscription)
 	if err != nil {
+		if errors.Is(err, repository.ErrOverlappingSubscription) {
+			h.handleError(w, err.Error(), err, http.StatusConflict)
+			return
+		}
+		if errors.Is(err, repository.ErrUnknownUser) {
+			h.handleError(w, err.Error(), err, http.StatusUnprocessableEntity)
+			return
+		}
 		fmt.Println(newSubscription)
 		h.handleError(w, "Failed to update subscription record", err, http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	subscriptionResponse := updatedSubsription.ToResponse()
+	subscriptionResponse := updatedSubsription.ToResponseIn(dateFormat)
+	subscriptionResponse.Warnings = h.budgetWarnings(updatedSubsription)
 	data, err := json.Marshal(subscriptionResponse)
 	if err != nil {
 		h.handleError(w, "Failed to marshal response", err, http.StatusInternalServerError)
@@ -356,13 +567,18 @@ func (h *SubscriptionHandler) DeleteSubscriptionRecord(w http.ResponseWriter, r
 }
 
 // @Summary Calculate subscriptin cost
-// @Description Calculating subscription cost based on filtering parametres
+// @Description Calculating subscription cost based on filtering parametres. Every record is charged its price
+// @Description on each of its billing days within the period
+// @Description Shared subscriptions are split between members by weight, so filtering by user counts only user's share
+// @Description Along with total cost returns totals per service category
 // @Tags subscriptions
 // @Produce json
-// @Param start_date query string false "Start date of period (MM-YYYY)"
-// @Param end_date query string false "End date of period (MM-YYYY)"
+// @Param start_date query string false "First day of period (MM-YYYY or YYYY-MM-DD), start of every record by default"
+// @Param end_date query string false "Last day of period (MM-YYYY or YYYY-MM-DD), current date by default"
 // @Param service_name query string false "Service name for filtering"
+// @Param category query string false "Service category for filtering, uncategorized for services without category"
 // @Param user_id query string fasle "User UUID for filtering"
+// @Param as_of query string false "Moment to calculate cost as data stood at (RFC 3339)"
 // @Success 200 {object} models.SubscriptionCostResponse
 // @Router /subscriptions/total-cost [get]
 func (h *SubscriptionHandler) CalculateSubscriptionCost(w http.ResponseWriter, r *http.Request) {
@@ -371,45 +587,164 @@ func (h *SubscriptionHandler) CalculateSubscriptionCost(w http.ResponseWriter, r
 
 	defer r.Body.Close()
 
+	subscriptionCost, ok := h.parseSubscriptionCost(w, r)
+	if !ok {
+		return
+	}
+
+	cost, err := h.repo.CalculateSubscriptionCost(ctx, subscriptionCost)
+	if err != nil {
+		h.handleError(w, "Failed to calculate subscription cost", err, http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := h.repo.CalculateSubscriptionCostBreakdown(ctx, subscriptionCost, models.CostGroupByCategory)
+	if err != nil {
+		h.handleError(w, "Failed to calculate subscription cost per category", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	subscriptionCostResponse := models.SubscriptionCostResponse{
+		Cost:       cost,
+		Categories: make([]models.SubscriptionCostItemResponse, 0, len(categories)),
+	}
+	for _, category := range categories {
+		subscriptionCostResponse.Categories = append(subscriptionCostResponse.Categories, models.SubscriptionCostItemResponse{Key: category.Key, Cost: category.Cost})
+	}
+	data, err := json.Marshal(subscriptionCostResponse)
+	if err != nil {
+		h.handleError(w, "Failed to calculate subscription cost", err, http.StatusInternalServerError)
+		return
+	}
+	w.Write(data)
+
+	h.log.Info("Subscription cost calculated successfully", "cost", cost)
+}
+
+// @Summary Calculate subscription cost breakdown
+// @Description Calculating subscription cost per service, category, cost center or tag based on filtering parametres.
+// @Description Records without cost center or tags are grouped under empty key, record with several tags counts for each of them.
+// @Description Besides JSON, breakdown can be exported as CSV, NDJSON or XLSX chosen by format query parameter or Accept header
+// @Tags subscriptions
+// @Produce json
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param start_date query string false "First day of period (MM-YYYY or YYYY-MM-DD), start of every record by default"
+// @Param end_date query string false "Last day of period (MM-YYYY or YYYY-MM-DD), current date by default"
+// @Param service_name query string false "Service name for filtering"
+// @Param category query string false "Service category for filtering, uncategorized for services without category"
+// @Param user_id query string false "User UUID for filtering"
+// @Param as_of query string false "Moment to calculate cost as data stood at (RFC 3339)"
+// @Param group_by query string false "Grouping key: service_name (default), category, cost_center or tag"
+// @Param format query string false "Response format: json (default), csv, ndjson or xlsx"
+// @Success 200 {object} models.SubscriptionCostBreakdownResponse
+// @Router /subscriptions/cost-breakdown [get]
+func (h *SubscriptionHandler) CalculateSubscriptionCostBreakdown(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	format, err := export.NegotiateFormat(r)
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	groupBy, err := models.ParseCostGroupBy(r.URL.Query().Get("group_by"))
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	subscriptionCost, ok := h.parseSubscriptionCost(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.repo.CalculateSubscriptionCostBreakdown(ctx, subscriptionCost, groupBy)
+	if err != nil {
+		h.handleError(w, "Failed to calculate subscription cost breakdown", err, http.StatusInternalServerError)
+		return
+	}
+
+	if format != export.FormatJSON {
+		h.exportSubscriptionCostBreakdown(w, format, groupBy, items)
+		return
+	}
+
+	response := models.SubscriptionCostBreakdownResponse{
+		GroupBy: groupBy,
+		Items:   make([]models.SubscriptionCostItemResponse, 0, len(items)),
+	}
+	for _, item := range items {
+		response.Items = append(response.Items, models.SubscriptionCostItemResponse{Key: item.Key, Cost: item.Cost})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Subscription cost breakdown calculated successfully", "group_by", groupBy, "groups", len(items))
+}
+
+// parseSubscriptionCost reads cost filtering parametres from query, writing error response if they are invalid
+func (h *SubscriptionHandler) parseSubscriptionCost(w http.ResponseWriter, r *http.Request) (*models.SubscriptionCost, bool) {
 	start_date := r.URL.Query().Get("start_date")
 	end_date := r.URL.Query().Get("end_date")
 	user_id := r.URL.Query().Get("user_id")
 	service_name := r.URL.Query().Get("service_name")
+	as_of := r.URL.Query().Get("as_of")
+	category := r.URL.Query().Get("category")
 
 	subscriptionCostRequest := models.SubscriptionCostRequest{
 		StartDate:   start_date,
 		EndDate:     end_date,
 		UserID:      user_id,
 		ServiceName: service_name,
+		AsOf:        as_of,
+		Category:    category,
 	}
 
 	subscriptionCost, err := subscriptionCostRequest.ToSubscriptionCost()
 	if err != nil {
 		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
 	if subscriptionCost.EndDate != nil && subscriptionCost.StartDate != nil && (*subscriptionCost).EndDate.Before(*subscriptionCost.StartDate) {
 		h.handleError(w, "end date must be bigger than start date", errors.New("Invalid dates"), http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
-	cost, err := h.repo.CalculateSubscriptionCost(ctx, subscriptionCost)
-	if err != nil {
-		h.handleError(w, "Failed to calculate subscription cost", err, http.StatusInternalServerError)
-		return
+	return subscriptionCost, true
+}
+
+// parseDateFormat reads format of dates in response from query or, if it is absent, from X-Date-Format header,
+// falling back to the default of API version and writing error response if format is invalid
+func (h *SubscriptionHandler) parseDateFormat(w http.ResponseWriter, r *http.Request) (models.DateFormat, bool) {
+	format := r.URL.Query().Get("date_format")
+	if format == "" {
+		format = r.Header.Get("X-Date-Format")
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	subscriptionCostResponse := models.SubscriptionCostResponse{Cost: cost}
-	data, err := json.Marshal(subscriptionCostResponse)
+	dateFormat, err := models.ParseDateFormat(format, defaultDateFormat(r, h.dateFormat))
 	if err != nil {
-		h.handleError(w, "Failed to calculate subscription cost", err, http.StatusInternalServerError)
-		return
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return "", false
 	}
-	w.Write(data)
 
-	h.log.Info("Subscription cost calculated successfully", "cost", cost)
+	return dateFormat, true
+}
+
+// budgetWarnings returns warnings about budgets the saved subscription record pushed over their limit
+func (h *SubscriptionHandler) budgetWarnings(subscription *models.Subscription) []string {
+	for _, status := range subscription.ExceededBudgets {
+		h.log.Warn("Budget exceeded", "budget_id", status.ID, "subscription_id", subscription.ID, "spend", status.Spend, "limit", status.MonthlyLimit)
+	}
+
+	return subscription.BudgetWarnings()
 }
 
 func (h *SubscriptionHandler) handleError(w http.ResponseWriter, message string, err error, status int) {
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+const importChunkSize = 500
+
+// maxDryRunKeys bounds rows a dry run remembers to detect duplicates across chunks,
+// real import finds them among saved records instead
+const maxDryRunKeys = 100000
+
+// @Summary Import subscription records from CSV
+// @Description Streams CSV file with header containing service_name, price, user_id, start_date and optional end_date columns.
+// @Description Rows are written in chunks, rows duplicating existing records or previous rows are skipped. In dry run rows
+// @Description are only validated, duplicates of previous rows are detected among the first 100000 unique rows and
+// @Description duplicates_incomplete is set if there were more. If import stops on error, response has the error along
+// @Description with counts of rows processed before it, records of saved chunks are kept
+// @Tags subscriptions
+// @Accept text/csv
+// @Produce json
+// @Param dry_run query bool false "Validate rows without writing them"
+// @Success 200 {object} models.ImportResponse
+// @Failure 400 {object} models.ImportResponse "CSV is malformed"
+// @Failure 500 {object} models.ImportResponse "Import stopped by failure"
+// @Router /subscriptions/import [post]
+func (h *SubscriptionHandler) ImportSubscriptionRecords(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "text/csv" {
+		http.Error(w, "Content-Type must be text/csv", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	response := models.ImportResponse{
+		DryRun: r.URL.Query().Get("dry_run") == "true",
+		Errors: []models.ImportRowError{},
+	}
+
+	reader := csv.NewReader(r.Body)
+	reader.FieldsPerRecord = -1
+
+	headerRecord, err := reader.Read()
+	if err != nil {
+		h.handleError(w, "Invalid CSV header", err, http.StatusBadRequest)
+		return
+	}
+
+	header, err := models.ParseCSVHeader(headerRecord)
+	if err != nil {
+		h.handleError(w, "Invalid CSV header", err, http.StatusBadRequest)
+		return
+	}
+
+	var chunk []*models.Subscription
+	var chunkRows []int
+
+	// Chunks of dry run are not saved, so rows of previous chunks are remembered to find duplicates of them
+	seen := make(map[string]struct{})
+
+	// abort stops import, reporting what was saved before the failure
+	abort := func(message string, err error, status int) {
+		h.log.Error(message, "error", err, "created", response.Created)
+		response.Error = message
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+	}
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		duplicates, err := h.repo.Duplicates(ctx, chunk)
+		if err != nil {
+			return err
+		}
+
+		var unique []*models.Subscription
+		var uniqueRows []int
+		for i, duplicate := range duplicates {
+			if !duplicate && response.DryRun {
+				key := importKey(chunk[i])
+				if _, exists := seen[key]; exists {
+					duplicate = true
+				} else if len(seen) < maxDryRunKeys {
+					seen[key] = struct{}{}
+				} else {
+					response.DuplicatesIncomplete = true
+				}
+			}
+
+			if duplicate {
+				response.Duplicates++
+				continue
+			}
+			unique = append(unique, chunk[i])
+			uniqueRows = append(uniqueRows, chunkRows[i])
+		}
+
+		chunk = nil
+		chunkRows = nil
+
+		if len(unique) == 0 {
+			return nil
+		}
+
+		if response.DryRun {
+			response.Created += len(unique)
+			return nil
+		}
+
+		errs := h.repo.CreateBatch(ctx, unique, true)
+		if errors.Join(errs...) != nil {
+			errs = h.repo.CreateBatch(ctx, unique, false)
+		}
+
+		for i, err := range errs {
+			if err != nil {
+				response.AddError(uniqueRows[i], err)
+			} else {
+				response.Created++
+			}
+		}
+
+		return nil
+	}
+
+	row := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				response.Rows++
+				response.AddError(row, err)
+				continue
+			}
+
+			abort("Failed to read CSV", err, http.StatusBadRequest)
+			return
+		}
+		response.Rows++
+
+		subscriptionRequest, err := header.ToSubscriptionRequest(record)
+		if err != nil {
+			response.AddError(row, err)
+			continue
+		}
+
+		subscription, err := subscriptionRequest.ToSubscription()
+		if err != nil {
+			response.AddError(row, err)
+			continue
+		}
+
+		chunk = append(chunk, subscription)
+		chunkRows = append(chunkRows, row)
+		if len(chunk) >= importChunkSize {
+			if err := flush(); err != nil {
+				abort("Failed to check duplicates", err, http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		abort("Failed to check duplicates", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Subscription records imported", "dry_run", response.DryRun, "rows", response.Rows, "created", response.Created, "duplicates", response.Duplicates, "failed", response.Failed)
+}
+
+// importKey identifies row by fields duplicates are detected by: service, price, user, start and end
+func importKey(subscription *models.Subscription) string {
+	end := ""
+	if subscription.EndDate != nil {
+		end = subscription.EndDate.Format(time.DateOnly)
+	}
+
+	return fmt.Sprintf("%s\x00%d\x00%s\x00%s\x00%s", subscription.ServiceName, subscription.Price, subscription.UserID, subscription.StartDate.Format(time.DateOnly), end)
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// @Summary Merge subscription records
+// @Description Combines overlapping or consecutive records of the same user, service, price and billing day into the record
+// @Description with the lowest ID covering their unified period, other records are deleted
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param merge body models.MergeRequest true "Records to merge"
+// @Param date_format query string false "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise"
+// @Param X-Date-Format header string false "Format of dates in response, used if date_format query parameter is absent"
+// @Success 200 {object} models.SubscriptionResponse
+// @Failure 404 {string} string "Record with one of ids not found"
+// @Failure 409 {string} string "Records can not be merged"
+// @Router /subscriptions/merge [post]
+func (h *SubscriptionHandler) MergeSubscriptionRecords(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var mergeRequest models.MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&mergeRequest); err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := mergeRequest.Validate(); err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	dateFormat, ok := h.parseDateFormat(w, r)
+	if !ok {
+		return
+	}
+
+	merged, err := h.repo.Merge(ctx, mergeRequest.IDs)
+	if err != nil {
+		if errors.Is(err, repository.ErrSubscriptionNotFound) {
+			h.handleError(w, err.Error(), err, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, repository.ErrMergeConflict) || errors.Is(err, repository.ErrOverlappingSubscription) {
+			h.handleError(w, err.Error(), err, http.StatusConflict)
+			return
+		}
+		h.handleError(w, "Failed to merge subscription records", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(merged.ToResponseIn(dateFormat))
+
+	h.log.Info("Subscription records merged successfully", "ids", mergeRequest.IDs, "id", merged.ID)
+}
+
+// @Summary Split subscription record
+// @Description Ends subscription record the day before given date and creates record continuing it
+// @Description from that date on the same billing day, e.g. to record price change. Month means its first day
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param split body models.SplitRequest true "Date to split at and new price"
+// @Param date_format query string false "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise"
+// @Param X-Date-Format header string false "Format of dates in response, used if date_format query parameter is absent"
+// @Success 201 {array} models.SubscriptionResponse
+// @Failure 404 {string} string "Record not found"
+// @Failure 409 {string} string "Record can not be split at this date"
+// @Router /subscriptions/{id}/split [post]
+func (h *SubscriptionHandler) SplitSubscriptionRecord(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	defer r.Body.Close()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, "Invalid id in request", err, http.StatusBadRequest)
+		return
+	}
+
+	var splitRequest models.SplitRequest
+	if err := json.NewDecoder(r.Body).Decode(&splitRequest); err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	from, err := splitRequest.ToDate()
+	if err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	dateFormat, ok := h.parseDateFormat(w, r)
+	if !ok {
+		return
+	}
+
+	parts, err := h.repo.Split(ctx, id, from, splitRequest.Price)
+	if err != nil {
+		if errors.Is(err, repository.ErrSubscriptionNotFound) {
+			h.handleError(w, err.Error(), err, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, repository.ErrInvalidSplit) || errors.Is(err, repository.ErrOverlappingSubscription) {
+			h.handleError(w, err.Error(), err, http.StatusConflict)
+			return
+		}
+		h.handleError(w, "Failed to split subscription record", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]*models.SubscriptionResponse, 0, len(parts))
+	for _, part := range parts {
+		response = append(response, part.ToResponseIn(dateFormat))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Subscription record split successfully", "id", id, "new_id", parts[1].ID)
+}
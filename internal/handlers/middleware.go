@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/requestmeta"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ActorHeader     = "X-Actor"
+	RequestIDHeader = "X-Request-ID"
+)
+
+// RequestMetaMiddleware stores the actor and request ID of every request in its context,
+// generating a request ID when the client did not send one
+func RequestMetaMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := requestmeta.WithRequestID(r.Context(), requestID)
+		ctx = requestmeta.WithActor(ctx, r.Header.Get(ActorHeader))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
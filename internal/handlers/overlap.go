@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// @Summary List overlapping subscription records
+// @Description Finds pairs of subscription records of the same user and service with overlapping periods,
+// @Description with overlap period and amount charged twice because of it
+// @Tags subscriptions
+// @Produce json
+// @Param user_id query string false "User UUID for filtering"
+// @Param service_name query string false "Service name for filtering"
+// @Success 200 {array} models.OverlapResponse
+// @Router /subscriptions/overlaps [get]
+func (h *SubscriptionHandler) ListOverlappingSubscriptionRecords(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	var userID *uuid.UUID
+	if value := r.URL.Query().Get("user_id"); value != "" {
+		userUUID, err := uuid.Parse(value)
+		if err != nil {
+			h.handleError(w, "Invalid user_id format, must be uuid", err, http.StatusBadRequest)
+			return
+		}
+		userID = &userUUID
+	}
+
+	overlaps, err := h.repo.ListOverlaps(ctx, userID, r.URL.Query().Get("service_name"))
+	if err != nil {
+		h.handleError(w, "Failed to list overlapping subscription records", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]*models.OverlapResponse, 0, len(overlaps))
+	for _, overlap := range overlaps {
+		response = append(response, overlap.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Overlapping subscription records listed successfully", "amount", len(response))
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type RenewalHandler struct {
+	repo repository.RenewalRepositoryInterface
+	log  *slog.Logger
+}
+
+func NewRenewalHandler(repo repository.RenewalRepositoryInterface, log *slog.Logger) *RenewalHandler {
+	return &RenewalHandler{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (h *RenewalHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/renewals/runs", h.ListRenewalRuns).Methods("GET")
+}
+
+// @Summary List renewal job runs
+// @Description Lists the latest runs of the job ending expired subscriptions and renewing auto-renewing ones, newest first
+// @Tags renewals
+// @Produce json
+// @Param limit query int false "Number of runs, from 1 to 100, 20 by default"
+// @Success 200 {array} models.RenewalRunResponse
+// @Router /renewals/runs [get]
+func (h *RenewalHandler) ListRenewalRuns(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	limit, err := models.ParseRenewalRunsLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	runs, err := h.repo.ListRuns(ctx, limit)
+	if err != nil {
+		h.handleError(w, "Failed to list renewal runs", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]*models.RenewalRunResponse, 0, len(runs))
+	for _, run := range runs {
+		response = append(response, run.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Renewal runs listed successfully", "amount", len(response))
+}
+
+func (h *RenewalHandler) handleError(w http.ResponseWriter, message string, err error, status int) {
+	http.Error(w, message, status)
+	h.log.Error(message, "error", err)
+}
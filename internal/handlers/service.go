@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type ServiceHandler struct {
+	repo repository.ServiceRepositoryInterface
+	log  *slog.Logger
+}
+
+func NewServiceHandler(repo repository.ServiceRepositoryInterface, log *slog.Logger) *ServiceHandler {
+	return &ServiceHandler{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (h *ServiceHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/services", h.ListServices).Methods("GET")
+	router.HandleFunc("/services/{service_name}/category", h.SetServiceCategory).Methods("PUT")
+	router.HandleFunc("/services/{service_name}/category", h.DeleteServiceCategory).Methods("DELETE")
+}
+
+// @Summary List services
+// @Description Lists services of subscription records and services with assigned category, with their categories
+// @Tags services
+// @Produce json
+// @Param category query string false "Category for filtering, uncategorized for services without category"
+// @Success 200 {array} models.ServiceCategoryResponse
+// @Router /services [get]
+func (h *ServiceHandler) ListServices(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	services, err := h.repo.List(ctx, models.NormalizeCategory(r.URL.Query().Get("category")))
+	if err != nil {
+		h.handleError(w, "Failed to list services", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.ServiceCategoryResponse, 0, len(services))
+	for _, service := range services {
+		response = append(response, service.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Services listed successfully", "amount", len(response))
+}
+
+// @Summary Set service category
+// @Description Assigns category to service, replacing previous one. Categories are case insensitive
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param service_name path string true "Service name"
+// @Param category body models.ServiceCategoryRequest true "Category"
+// @Success 200 {object} models.ServiceCategoryResponse
+// @Router /services/{service_name}/category [put]
+func (h *ServiceHandler) SetServiceCategory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	defer r.Body.Close()
+
+	serviceName := mux.Vars(r)["service_name"]
+
+	var categoryRequest models.ServiceCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&categoryRequest); err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	category, err := categoryRequest.ToCategory()
+	if err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	service, err := h.repo.SetCategory(ctx, serviceName, category)
+	if err != nil {
+		h.handleError(w, "Failed to set service category", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(service.ToResponse())
+
+	h.log.Info("Service category set successfully", "service_name", serviceName, "category", category)
+}
+
+// @Summary Delete service category
+// @Description Removes category of service, so it becomes uncategorized
+// @Tags services
+// @Param service_name path string true "Service name"
+// @Success 204 "No content"
+// @Router /services/{service_name}/category [delete]
+func (h *ServiceHandler) DeleteServiceCategory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	serviceName := mux.Vars(r)["service_name"]
+
+	if err := h.repo.DeleteCategory(ctx, serviceName); err != nil {
+		h.handleError(w, "Failed to delete service category", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	h.log.Info("Service category deleted successfully", "service_name", serviceName)
+}
+
+func (h *ServiceHandler) handleError(w http.ResponseWriter, message string, err error, status int) {
+	http.Error(w, message, status)
+	h.log.Error(message, "error", err)
+}
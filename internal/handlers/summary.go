@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// @Summary Get user subscription summary
+// @Description Returns active subscriptions of user with their monthly cost, spend this year, the most expensive service,
+// @Description subscriptions ending soon and total spend over all time
+// @Tags subscriptions
+// @Produce json
+// @Param user_id path string true "User UUID"
+// @Param ending_within query int false "Number of months after the current one to report ending subscriptions for, from 0 to 12, 1 by default"
+// @Param date_format query string false "Format of dates in response: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD, if absent YYYY-MM-DD in v2 and server default otherwise"
+// @Param X-Date-Format header string false "Format of dates in response, used if date_format query parameter is absent"
+// @Success 200 {object} models.UserSummaryResponse
+// @Router /users/{user_id}/summary [get]
+func (h *SubscriptionHandler) GetUserSummary(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	userID, err := uuid.Parse(mux.Vars(r)["user_id"])
+	if err != nil {
+		h.handleError(w, "Invalid user_id format, must be uuid", err, http.StatusBadRequest)
+		return
+	}
+
+	endingWithin, err := models.ParseEndingWithin(r.URL.Query().Get("ending_within"))
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	dateFormat, ok := h.parseDateFormat(w, r)
+	if !ok {
+		return
+	}
+
+	currentMonth, _ := models.ParseMonth("")
+
+	active, err := h.repo.List(ctx, &models.SubscriptionFilter{UserID: &userID, ActiveAt: &currentMonth})
+	if err != nil {
+		h.handleError(w, "Failed to list subsription records", err, http.StatusInternalServerError)
+		return
+	}
+
+	yearSpend, lifetimeTotal, err := h.repo.UserSpend(ctx, userID, currentMonth)
+	if err != nil {
+		h.handleError(w, "Failed to calculate user spend", err, http.StatusInternalServerError)
+		return
+	}
+
+	summary := models.NewUserSummary(userID, currentMonth, active, endingWithin)
+	summary.YearSpend = yearSpend
+	summary.LifetimeTotal = lifetimeTotal
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary.ToResponseIn(dateFormat))
+
+	h.log.Info("User summary computed successfully", "user_id", userID)
+}
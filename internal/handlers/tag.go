@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type TagHandler struct {
+	repo repository.TagRepositoryInterface
+	log  *slog.Logger
+}
+
+func NewTagHandler(repo repository.TagRepositoryInterface, log *slog.Logger) *TagHandler {
+	return &TagHandler{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (h *TagHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/tags", h.ListTags).Methods("GET")
+	router.HandleFunc("/tags/merge", h.MergeTags).Methods("POST")
+}
+
+// @Summary List tags
+// @Description Lists tags used on subscription records with number of records marked with each of them
+// @Tags tags
+// @Produce json
+// @Success 200 {array} models.TagResponse
+// @Router /tags [get]
+func (h *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	tags, err := h.repo.List(ctx)
+	if err != nil {
+		h.handleError(w, "Failed to list tags", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		response = append(response, tag.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Tags listed successfully", "amount", len(response))
+}
+
+// @Summary Rename or merge tags
+// @Description Replaces given tags with another one across all subscription records, renaming is merge of a single tag
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param merge body models.TagMergeRequest true "Tags to replace and replacement"
+// @Success 200 {object} models.TagMergeResponse
+// @Router /tags/merge [post]
+func (h *TagHandler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var mergeRequest models.TagMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&mergeRequest); err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := mergeRequest.Validate()
+	if err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.repo.Merge(ctx, from, to)
+	if err != nil {
+		h.handleError(w, "Failed to merge tags", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.TagMergeResponse{To: to, Updated: updated})
+
+	h.log.Info("Tags merged successfully", "from", from, "to", to, "updated", updated)
+}
+
+func (h *TagHandler) handleError(w http.ResponseWriter, message string, err error, status int) {
+	http.Error(w, message, status)
+	h.log.Error(message, "error", err)
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type UserHandler struct {
+	repo repository.UserRepositoryInterface
+	log  *slog.Logger
+}
+
+func NewUserHandler(repo repository.UserRepositoryInterface, log *slog.Logger) *UserHandler {
+	return &UserHandler{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (h *UserHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/users", h.CreateUser).Methods("POST")
+	router.HandleFunc("/users", h.ListUsers).Methods("GET")
+
+	router.HandleFunc("/users/{user_id}", h.GetUser).Methods("GET")
+	router.HandleFunc("/users/{user_id}", h.UpdateUser).Methods("PUT")
+	router.HandleFunc("/users/{user_id}", h.DeleteUser).Methods("DELETE")
+}
+
+// @Summary Create user
+// @Description Creates user subscription records can refer to
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user body models.UserRequest true "User data"
+// @Success 201 {object} models.UserResponse
+// @Failure 409 {string} string "User with the same id or email already exists"
+// @Router /users [post]
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var userRequest models.UserRequest
+	if err := json.NewDecoder(r.Body).Decode(&userRequest); err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := userRequest.ToUser()
+	if err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.Create(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrUserExists) {
+			h.handleError(w, err.Error(), err, http.StatusConflict)
+			return
+		}
+		h.handleError(w, "Failed to create user", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user.ToResponse())
+
+	h.log.Info("User created successfully", "user_id", user.ID)
+}
+
+// @Summary Get user
+// @Description Gets user by UUID
+// @Tags users
+// @Produce json
+// @Param user_id path string true "User UUID"
+// @Success 200 {object} models.UserResponse
+// @Failure 404 {string} string "User not found"
+// @Router /users/{user_id} [get]
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	userID, err := uuid.Parse(mux.Vars(r)["user_id"])
+	if err != nil {
+		h.handleError(w, "Invalid user_id format, must be uuid", err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.repo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			h.handleError(w, err.Error(), err, http.StatusNotFound)
+			return
+		}
+		h.handleError(w, "Failed to get user", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user.ToResponse())
+
+	h.log.Info("User got successfully", "user_id", userID)
+}
+
+// @Summary List users
+// @Description Lists users in order of creation
+// @Tags users
+// @Produce json
+// @Success 200 {array} models.UserResponse
+// @Router /users [get]
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	users, err := h.repo.List(ctx)
+	if err != nil {
+		h.handleError(w, "Failed to list users", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]*models.UserResponse, 0, len(users))
+	for _, user := range users {
+		response = append(response, user.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Users listed successfully", "amount", len(response))
+}
+
+// @Summary Update user
+// @Description Replaces profile data of user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User UUID"
+// @Param user body models.UserRequest true "User data"
+// @Success 200 {object} models.UserResponse
+// @Failure 404 {string} string "User not found"
+// @Failure 409 {string} string "User with the same email already exists"
+// @Router /users/{user_id} [put]
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	defer r.Body.Close()
+
+	userID, err := uuid.Parse(mux.Vars(r)["user_id"])
+	if err != nil {
+		h.handleError(w, "Invalid user_id format, must be uuid", err, http.StatusBadRequest)
+		return
+	}
+
+	var userRequest models.UserRequest
+	if err := json.NewDecoder(r.Body).Decode(&userRequest); err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := userRequest.ToUser()
+	if err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+	user.ID = userID
+
+	updatedUser, err := h.repo.Update(ctx, user)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			h.handleError(w, err.Error(), err, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, repository.ErrUserExists) {
+			h.handleError(w, err.Error(), err, http.StatusConflict)
+			return
+		}
+		h.handleError(w, "Failed to update user", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updatedUser.ToResponse())
+
+	h.log.Info("User updated successfully", "user_id", userID)
+}
+
+// @Summary Delete user
+// @Description Deletes user with budgets. By default user with subscription records or sharing records of others is not deleted,
+// @Description with cascade=soft_delete records are moved to deleted_subscription_record table and deleted like through DELETE /subscriptions/{id}
+// @Description and user is removed from members of shared records
+// @Tags users
+// @Param user_id path string true "User UUID"
+// @Param cascade query string false "What to do with subscription records: block (default) or soft_delete"
+// @Success 204 "No content"
+// @Failure 404 {string} string "User not found"
+// @Failure 409 {string} string "User has or shares subscription records"
+// @Router /users/{user_id} [delete]
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	userID, err := uuid.Parse(mux.Vars(r)["user_id"])
+	if err != nil {
+		h.handleError(w, "Invalid user_id format, must be uuid", err, http.StatusBadRequest)
+		return
+	}
+
+	mode, err := models.ParseUserDeleteMode(r.URL.Query().Get("cascade"))
+	if err != nil {
+		h.handleError(w, "Invalid request", err, http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.repo.DeleteByID(ctx, userID, mode)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			h.handleError(w, err.Error(), err, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, repository.ErrUserHasSubscriptions) {
+			h.handleError(w, err.Error(), err, http.StatusConflict)
+			return
+		}
+		h.handleError(w, "Failed to delete user", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	h.log.Info("User deleted successfully", "user_id", userID, "soft_deleted_records", deleted)
+}
+
+func (h *UserHandler) handleError(w http.ResponseWriter, message string, err error, status int) {
+	http.Error(w, message, status)
+	h.log.Error(message, "error", err)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// APIVersions are versions REST routes are mounted under, each as /<version> path prefix. Handlers
+// changing response shape in a version check it with APIVersion
+var APIVersions = []string{APIVersion1, APIVersion2}
+
+// versionDateFormats are formats dates of subscription records are written in when request doesn't choose one,
+// versions absent here use the server default. Since v2 dates are written with day precision, as records
+// end and are billed on any day of month
+var versionDateFormats = map[string]models.DateFormat{
+	APIVersion2: models.DateFormatDay,
+}
+
+const (
+	APIVersion1 = "v1"
+	APIVersion2 = "v2"
+
+	// LegacyAPIVersion is version unversioned paths are aliases of
+	LegacyAPIVersion = APIVersion1
+)
+
+// LegacyRoutesDeprecatedAt is the moment unversioned paths were deprecated in favour of versioned ones
+var LegacyRoutesDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+type RouteRegistrar interface {
+	RegisterRoutes(router *mux.Router)
+}
+
+type apiVersionKey struct{}
+
+// RegisterVersionedRoutes mounts routes of registrars under prefix of every API version and, for clients
+// written before versioning, at the root as deprecated aliases of LegacyAPIVersion removed after sunset
+func RegisterVersionedRoutes(router *mux.Router, sunset time.Time, registrars ...RouteRegistrar) {
+	for _, version := range APIVersions {
+		versioned := router.PathPrefix("/" + version).Subrouter()
+		versioned.Use(apiVersionMiddleware(version))
+		for _, registrar := range registrars {
+			registrar.RegisterRoutes(versioned)
+		}
+	}
+
+	legacy := router.NewRoute().Subrouter()
+	legacy.Use(apiVersionMiddleware(LegacyAPIVersion), deprecationMiddleware(LegacyRoutesDeprecatedAt, sunset))
+	for _, registrar := range registrars {
+		registrar.RegisterRoutes(legacy)
+	}
+}
+
+// APIVersion returns version of API request was routed to
+func APIVersion(r *http.Request) string {
+	version, ok := r.Context().Value(apiVersionKey{}).(string)
+	if !ok {
+		return LegacyAPIVersion
+	}
+
+	return version
+}
+
+// defaultDateFormat returns format dates of subscription records are written in by default in API version
+// of request, serverDefault unless the version has its own
+func defaultDateFormat(r *http.Request, serverDefault models.DateFormat) models.DateFormat {
+	if format, exists := versionDateFormats[APIVersion(r)]; exists {
+		return format
+	}
+
+	return serverDefault
+}
+
+func apiVersionMiddleware(version string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), apiVersionKey{}, version)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// deprecationMiddleware marks responses of deprecated routes with Deprecation (RFC 9745) and Sunset (RFC 8594)
+// headers and links the same route of LegacyAPIVersion as successor
+func deprecationMiddleware(deprecatedAt, sunset time.Time) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", deprecatedAt.Unix()))
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			w.Header().Set("Link", fmt.Sprintf(`</%s%s>; rel="successor-version"`, LegacyAPIVersion, r.URL.Path))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
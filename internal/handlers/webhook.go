@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type WebhookHandler struct {
+	repo repository.WebhookRepositoryInterface
+	log  *slog.Logger
+}
+
+func NewWebhookHandler(repo repository.WebhookRepositoryInterface, log *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (h *WebhookHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/webhooks", h.CreateWebhook).Methods("POST")
+	router.HandleFunc("/webhooks", h.ListWebhooks).Methods("GET")
+
+	router.HandleFunc("/webhooks/{id:[0-9]+}", h.GetWebhook).Methods("GET")
+	router.HandleFunc("/webhooks/{id:[0-9]+}", h.UpdateWebhook).Methods("PUT")
+	router.HandleFunc("/webhooks/{id:[0-9]+}", h.DeleteWebhook).Methods("DELETE")
+	router.HandleFunc("/webhooks/{id:[0-9]+}/deliveries", h.ListWebhookDeliveries).Methods("GET")
+}
+
+// @Summary Register webhook
+// @Description Registers URL to receive HMAC-signed notifications about renewing and ending subscriptions.
+// @Description Secret is returned only in this response
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body models.WebhookRequest true "Webhook data"
+// @Success 201 {object} models.WebhookResponse
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var webhookRequest models.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&webhookRequest); err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := webhookRequest.ToWebhook()
+	if err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	if webhook.Secret == "" {
+		webhook.Secret, err = models.GenerateWebhookSecret()
+		if err != nil {
+			h.handleError(w, "Failed to create webhook", err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := h.repo.Create(ctx, webhook); err != nil {
+		h.handleError(w, "Failed to create webhook", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := webhook.ToResponse()
+	response.Secret = webhook.Secret
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Webhook created successfully", "id", webhook.ID)
+}
+
+// @Summary Get webhook by ID
+// @Description Gets webhook by ID
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.WebhookResponse
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, "Invalid id in request", err, http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		h.handleError(w, "Failed to get webhook", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook.ToResponse())
+
+	h.log.Info("Webhook got successfully", "id", id)
+}
+
+// @Summary List webhooks
+// @Description Lists registered webhooks
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} models.WebhookResponse
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	webhooks, err := h.repo.List(ctx)
+	if err != nil {
+		h.handleError(w, "Failed to list webhooks", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]*models.WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, webhook.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Webhooks listed successfully", "amount", len(response))
+}
+
+// @Summary Update webhook by ID
+// @Description Makes full update of webhook by ID, secret is kept if it is empty
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param webhook body models.WebhookRequest true "New data for webhook"
+// @Success 200 {object} models.WebhookResponse
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, "Invalid id in request", err, http.StatusBadRequest)
+		return
+	}
+
+	var webhookRequest models.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&webhookRequest); err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := webhookRequest.ToWebhook()
+	if err != nil {
+		h.handleError(w, "Invalid request body", err, http.StatusBadRequest)
+		return
+	}
+	webhook.ID = id
+
+	updatedWebhook, err := h.repo.Update(ctx, webhook)
+	if err != nil {
+		h.handleError(w, "Failed to update webhook", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updatedWebhook.ToResponse())
+
+	h.log.Info("Webhook updated successfully", "id", id)
+}
+
+// @Summary Delete webhook by ID
+// @Description Deletes webhook by ID together with its delivery log
+// @Tags webhooks
+// @Param id path int true "Webhook ID"
+// @Success 204 "No content"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, "Invalid id in request", err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.DeleteByID(ctx, id); err != nil {
+		h.handleError(w, "Failed to delete webhook", err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.log.Info("Webhook deleted successfully", "id", id)
+}
+
+// @Summary List webhook deliveries
+// @Description Returns delivery log of webhook, newest first
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {array} models.WebhookDeliveryResponse
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	defer r.Body.Close()
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, "Invalid id in request", err, http.StatusBadRequest)
+		return
+	}
+
+	deliveries, err := h.repo.ListDeliveries(ctx, id)
+	if err != nil {
+		h.handleError(w, "Failed to list webhook deliveries", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]*models.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, delivery.ToResponse())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+
+	h.log.Info("Webhook deliveries listed successfully", "id", id, "amount", len(response))
+}
+
+func (h *WebhookHandler) handleError(w http.ResponseWriter, message string, err error, status int) {
+	http.Error(w, message, status)
+	h.log.Error(message, "error", err)
+}
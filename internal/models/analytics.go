@@ -0,0 +1,248 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	MaxAnalyticsMonths = 120
+	MaxAnalyticsLimit  = 100
+
+	TopServicesBySpend       = "spend"
+	TopServicesBySubscribers = "subscribers"
+)
+
+// AnalyticsPeriod is an inclusive range of months analytics is computed over
+type AnalyticsPeriod struct {
+	From time.Time
+	To   time.Time
+}
+
+type ServiceSpend struct {
+	ServiceName string
+	Spend       int
+	Subscribers int
+}
+
+type MonthlySpend struct {
+	Month  time.Time
+	Spend  int
+	Change int
+	Growth *float64
+}
+
+type MonthlyChurn struct {
+	Month     time.Time
+	Active    int
+	Started   int
+	Ended     int
+	ChurnRate *float64
+}
+
+type ServiceLifetime struct {
+	ServiceName   string
+	Subscriptions int
+	AverageMonths float64
+}
+
+// @Description Spend and subscriber count of a service over the period
+type ServiceSpendResponse struct {
+	// @Description Name of the service
+	// @Example Yandex Plus
+	ServiceName string `json:"service_name"`
+
+	// @Description Integer sum of monthly charges of the service over the period
+	// @Example 4788
+	Spend int `json:"spend"`
+
+	// @Description Number of distinct users subscribed to the service during the period
+	// @Example 3
+	Subscribers int `json:"subscribers"`
+}
+
+// @Description Response with top services of the period
+type TopServicesResponse struct {
+	// @Description First month of the period, format: MM-YYYY
+	// @Example 01-2025
+	From string `json:"from"`
+
+	// @Description Last month of the period, format: MM-YYYY
+	// @Example 12-2025
+	To string `json:"to"`
+
+	// @Description Field services are ranked by
+	// @Example spend
+	By string `json:"by"`
+
+	// @Description Services, best ranked first
+	Services []ServiceSpendResponse `json:"services"`
+}
+
+// @Description Spend of a month compared to the previous one
+type MonthlySpendResponse struct {
+	// @Description Month and year, format: MM-YYYY
+	// @Example 08-2025
+	Month string `json:"month"`
+
+	// @Description Integer sum of charges of the month
+	// @Example 1197
+	Spend int `json:"spend"`
+
+	// @Description Difference with spend of the previous month
+	// @Example 399
+	Change int `json:"change"`
+
+	// @Description Growth relative to the previous month in percent, null if previous month had no spend
+	// @Example 50
+	Growth *float64 `json:"growth"`
+}
+
+// @Description Response with month-over-month spend growth
+type SpendGrowthResponse struct {
+	// @Description Months of the period in chronological order
+	Months []MonthlySpendResponse `json:"months"`
+}
+
+// @Description Subscriptions started and ended in a month
+type MonthlyChurnResponse struct {
+	// @Description Month and year, format: MM-YYYY
+	// @Example 08-2025
+	Month string `json:"month"`
+
+	// @Description Number of subscriptions active in the month
+	// @Example 12
+	Active int `json:"active"`
+
+	// @Description Number of subscriptions whose first month is this one
+	// @Example 2
+	Started int `json:"started"`
+
+	// @Description Number of subscriptions whose last month is this one
+	// @Example 1
+	Ended int `json:"ended"`
+
+	// @Description Share of active subscriptions ended in the month in percent, null if none were active
+	// @Example 8.33
+	ChurnRate *float64 `json:"churn_rate"`
+}
+
+// @Description Response with new and ended subscriptions per month
+type ChurnResponse struct {
+	// @Description Months of the period in chronological order
+	Months []MonthlyChurnResponse `json:"months"`
+}
+
+// @Description Average lifetime of subscriptions to a service
+type ServiceLifetimeResponse struct {
+	// @Description Name of the service
+	// @Example Yandex Plus
+	ServiceName string `json:"service_name"`
+
+	// @Description Number of subscriptions, continuous records of the same user counted once
+	// @Example 5
+	Subscriptions int `json:"subscriptions"`
+
+	// @Description Average number of months subscription lasts, active ones counted up to the current month
+	// @Example 7.4
+	AverageMonths float64 `json:"average_months"`
+}
+
+// @Description Response with average subscription lifetime per service
+type LifetimeResponse struct {
+	// @Description Services in alphabetical order
+	Services []ServiceLifetimeResponse `json:"services"`
+}
+
+// ParseAnalyticsPeriod parses inclusive period of months, by default the last 12 months
+// including the current one
+func ParseAnalyticsPeriod(from, to string) (*AnalyticsPeriod, error) {
+	end, err := ParseMonth(to)
+	if err != nil {
+		return nil, err
+	}
+
+	start := end.AddDate(0, -11, 0)
+	if from != "" {
+		start, err = ParseMonth(from)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if start.After(end) {
+		return nil, errors.New("from must not be after to")
+	}
+
+	if start.AddDate(0, MaxAnalyticsMonths, 0).Before(end.AddDate(0, 1, 0)) {
+		return nil, errors.New("Period must not be longer than 120 months")
+	}
+
+	return &AnalyticsPeriod{From: start, To: end}, nil
+}
+
+// ParseTopServices parses ranking field and number of services, spend and 10 by default
+func ParseTopServices(by, limit string) (string, int, error) {
+	if by == "" {
+		by = TopServicesBySpend
+	}
+
+	if by != TopServicesBySpend && by != TopServicesBySubscribers {
+		return "", 0, errors.New("by must be spend or subscribers")
+	}
+
+	if limit == "" {
+		return by, 10, nil
+	}
+
+	number, err := strconv.Atoi(limit)
+	if err != nil || number < 1 || number > MaxAnalyticsLimit {
+		return "", 0, errors.New("limit must be integer from 1 to 100")
+	}
+
+	return by, number, nil
+}
+
+func (s ServiceSpend) ToResponse() ServiceSpendResponse {
+	return ServiceSpendResponse{
+		ServiceName: s.ServiceName,
+		Spend:       s.Spend,
+		Subscribers: s.Subscribers,
+	}
+}
+
+func (m MonthlySpend) ToResponse() MonthlySpendResponse {
+	return MonthlySpendResponse{
+		Month:  formatDate(m.Month),
+		Spend:  m.Spend,
+		Change: m.Change,
+		Growth: m.Growth,
+	}
+}
+
+func (m MonthlyChurn) ToResponse() MonthlyChurnResponse {
+	return MonthlyChurnResponse{
+		Month:     formatDate(m.Month),
+		Active:    m.Active,
+		Started:   m.Started,
+		Ended:     m.Ended,
+		ChurnRate: m.ChurnRate,
+	}
+}
+
+func (l ServiceLifetime) ToResponse() ServiceLifetimeResponse {
+	return ServiceLifetimeResponse{
+		ServiceName:   l.ServiceName,
+		Subscriptions: l.Subscriptions,
+		AverageMonths: l.AverageMonths,
+	}
+}
+
+func (p AnalyticsPeriod) FromString() string {
+	return formatDate(p.From)
+}
+
+func (p AnalyticsPeriod) ToString() string {
+	return formatDate(p.To)
+}
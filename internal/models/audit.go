@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	AuditOperationCreate = "create"
+	AuditOperationUpdate = "update"
+	AuditOperationDelete = "delete"
+)
+
+type AuditEntry struct {
+	ID             int64
+	SubscriptionID int
+	Operation      string
+	Actor          string
+	RequestID      string
+	ChangedAt      time.Time
+	Before         json.RawMessage
+	After          json.RawMessage
+}
+
+type AuditFilter struct {
+	SubscriptionID *int
+	Actor          string
+	From           *time.Time
+	To             *time.Time
+}
+
+// @Description Audit log entry describing a single change of subscription record
+type AuditEntryResponse struct {
+	// @Description Integer ID of audit entry
+	// @Example 1
+	ID int64 `json:"id"`
+
+	// @Description Integer ID of changed subscription record
+	// @Example 1
+	SubscriptionID int `json:"subscription_id"`
+
+	// @Description Kind of change: create, update or delete
+	// @Example update
+	Operation string `json:"operation"`
+
+	// @Description Who made the change, taken from X-Actor header
+	// @Example alice
+	Actor string `json:"actor"`
+
+	// @Description ID of request that made the change, taken from X-Request-ID header
+	// @Example 0b7c6a4e-5f3e-4a8e-9b0e-3f1d2c4b5a69
+	RequestID string `json:"request_id"`
+
+	// @Description Time of change in RFC 3339 format
+	// @Example 2025-07-01T12:00:00Z
+	ChangedAt string `json:"changed_at"`
+
+	// @Description State of subscription record before the change
+	Before json.RawMessage `json:"before" swaggertype:"object"`
+
+	// @Description State of subscription record after the change
+	After json.RawMessage `json:"after" swaggertype:"object"`
+}
+
+func (e AuditEntry) ToResponse() *AuditEntryResponse {
+	return &AuditEntryResponse{
+		ID:             e.ID,
+		SubscriptionID: e.SubscriptionID,
+		Operation:      e.Operation,
+		Actor:          e.Actor,
+		RequestID:      e.RequestID,
+		ChangedAt:      e.ChangedAt.UTC().Format(time.RFC3339),
+		Before:         e.Before,
+		After:          e.After,
+	}
+}
+
+func ParseAuditFilter(actor, from, to string) (*AuditFilter, error) {
+	filter := AuditFilter{Actor: actor}
+
+	if from != "" {
+		fromTime, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid from format, must be RFC 3339: %v", err)
+		}
+		filter.From = &fromTime
+	}
+
+	if to != "" {
+		toTime, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid to format, must be RFC 3339: %v", err)
+		}
+		filter.To = &toTime
+	}
+
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+
+	return &filter, nil
+}
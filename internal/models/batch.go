@@ -0,0 +1,39 @@
+package models
+
+const (
+	BatchModeAtomic  = "atomic"
+	BatchModePartial = "partial"
+)
+
+// @Description Result of creating single subscription record of a batch
+type BatchItemResponse struct {
+	// @Description Position of subscription record in request array
+	// @Example 0
+	Index int `json:"index"`
+
+	// @Description Integer ID of created subscription record
+	// @Example 1
+	ID *int `json:"id,omitempty"`
+
+	// @Description Error that prevented creating subscription record
+	// @Example Invalid date format, should be like MM-YYYY
+	Error string `json:"error,omitempty"`
+}
+
+// @Description Response with per-item results of batch creation
+type BatchResponse struct {
+	// @Description Batch mode: atomic or partial
+	// @Example atomic
+	Mode string `json:"mode"`
+
+	// @Description Number of created subscription records
+	// @Example 2
+	Created int `json:"created"`
+
+	// @Description Number of failed subscription records
+	// @Example 0
+	Failed int `json:"failed"`
+
+	// @Description Per-item results in the order of request array
+	Items []BatchItemResponse `json:"items"`
+}
@@ -0,0 +1,294 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxBudgetAlertsLimit is the largest number of budget alerts listed at once
+	MaxBudgetAlertsLimit = 100
+
+	// MaxBudgetCheckMonths bounds number of months checked against budgets on write of subscription record
+	MaxBudgetCheckMonths = 12
+)
+
+type Budget struct {
+	ID           int
+	UserID       *uuid.UUID
+	ServiceName  *string
+	MonthlyLimit int
+	CreatedAt    time.Time
+}
+
+type BudgetStatus struct {
+	Budget
+	Month time.Time
+	Spend int
+}
+
+// @Description Request to create or update monthly budget, scoped to user, service or both
+type BudgetRequest struct {
+	// @Description User's UUID the budget applies to
+	// @Example 60601fee-2bf1-4721-ae6f-7636e79a0cba
+	UserID string `json:"user_id"`
+
+	// @Description Service Name the budget applies to
+	// @Example Yandex Plus
+	ServiceName string `json:"service_name"`
+
+	// @Description Monthly spend limit (integer number of rubles)
+	// @Example 1000
+	MonthlyLimit int `json:"monthly_limit"`
+}
+
+// @Description Response with information about budget
+type BudgetResponse struct {
+	// @Description Integer ID of budget
+	// @Example 1
+	ID int `json:"id"`
+
+	// @Description User's UUID the budget applies to
+	// @Example 60601fee-2bf1-4721-ae6f-7636e79a0cba
+	UserID *string `json:"user_id"`
+
+	// @Description Service Name the budget applies to
+	// @Example Yandex Plus
+	ServiceName *string `json:"service_name"`
+
+	// @Description Monthly spend limit (integer number of rubles)
+	// @Example 1000
+	MonthlyLimit int `json:"monthly_limit"`
+}
+
+// @Description Comparison of budget against actual spend of a month
+type BudgetStatusResponse struct {
+	BudgetResponse
+
+	// @Description Month and year of comparison, format: MM-YYYY
+	// @Example 07-2025
+	Month string `json:"month"`
+
+	// @Description Actual spend of the month within budget scope
+	// @Example 1200
+	Spend int `json:"spend"`
+
+	// @Description Limit left for the month, negative when budget is overrun
+	// @Example -200
+	Remaining int `json:"remaining"`
+
+	// @Description Whether spend exceeds limit
+	// @Example true
+	Exceeded bool `json:"exceeded"`
+}
+
+// BudgetAlert is raised when write of subscription record pushes budget over its limit
+type BudgetAlert struct {
+	ID             int
+	BudgetID       int
+	SubscriptionID int
+	Month          time.Time
+	MonthlyLimit   int
+	Spend          int
+	Actor          string
+	RequestID      string
+	CreatedAt      time.Time
+}
+
+type BudgetAlertFilter struct {
+	BudgetID *int
+	Limit    int
+}
+
+// @Description Alert raised when write of subscription record pushed budget over its limit
+type BudgetAlertResponse struct {
+	// @Description Integer ID of alert
+	// @Example 1
+	ID int `json:"id"`
+
+	// @Description Integer ID of exceeded budget
+	// @Example 1
+	BudgetID int `json:"budget_id"`
+
+	// @Description Integer ID of subscription record whose write exceeded budget
+	// @Example 1
+	SubscriptionID int `json:"subscription_id"`
+
+	// @Description Month and year budget is exceeded in, format: MM-YYYY
+	// @Example 07-2025
+	Month string `json:"month"`
+
+	// @Description Monthly spend limit at the moment of alert
+	// @Example 1000
+	MonthlyLimit int `json:"monthly_limit"`
+
+	// @Description Spend of the month right after the write
+	// @Example 1200
+	Spend int `json:"spend"`
+
+	// @Description Actor who made the write
+	// @Example admin
+	Actor string `json:"actor"`
+
+	// @Description ID of request that made the write
+	// @Example 5f2b8c1e-8d4a-4c1b-9a57-0f6d1c3e2a10
+	RequestID string `json:"request_id"`
+
+	// @Description Moment alert was raised at
+	// @Example 2025-07-01T00:00:00Z
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (req BudgetRequest) ToBudget() (*Budget, error) {
+	var budget Budget
+
+	if req.UserID != "" {
+		userUUID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid user_id format, must be uuid: %v", err)
+		}
+		budget.UserID = &userUUID
+	}
+
+	if req.ServiceName != "" {
+		serviceName := req.ServiceName
+		budget.ServiceName = &serviceName
+	}
+
+	if budget.UserID == nil && budget.ServiceName == nil {
+		return nil, errors.New("Budget must be scoped to user_id, service_name or both")
+	}
+
+	if req.MonthlyLimit < 0 {
+		return nil, errors.New("monthly_limit must not be negative")
+	}
+	budget.MonthlyLimit = req.MonthlyLimit
+
+	return &budget, nil
+}
+
+func (b Budget) ToResponse() *BudgetResponse {
+	resp := BudgetResponse{
+		ID:           b.ID,
+		ServiceName:  b.ServiceName,
+		MonthlyLimit: b.MonthlyLimit,
+	}
+
+	if b.UserID != nil {
+		temp := b.UserID.String()
+		resp.UserID = &temp
+	}
+
+	return &resp
+}
+
+// ParseBudgetAlertFilter parses budget alerts are listed for, all budgets by default, and number
+// of the latest alerts, 20 by default
+func ParseBudgetAlertFilter(budgetID, limit string) (*BudgetAlertFilter, error) {
+	filter := BudgetAlertFilter{Limit: 20}
+
+	if budgetID != "" {
+		id, err := strconv.Atoi(budgetID)
+		if err != nil {
+			return nil, errors.New("budget_id must be integer")
+		}
+		filter.BudgetID = &id
+	}
+
+	if limit != "" {
+		number, err := strconv.Atoi(limit)
+		if err != nil || number < 1 || number > MaxBudgetAlertsLimit {
+			return nil, errors.New("limit must be integer from 1 to 100")
+		}
+		filter.Limit = number
+	}
+
+	return &filter, nil
+}
+
+func (a BudgetAlert) ToResponse() *BudgetAlertResponse {
+	return &BudgetAlertResponse{
+		ID:             a.ID,
+		BudgetID:       a.BudgetID,
+		SubscriptionID: a.SubscriptionID,
+		Month:          formatDate(a.Month),
+		MonthlyLimit:   a.MonthlyLimit,
+		Spend:          a.Spend,
+		Actor:          a.Actor,
+		RequestID:      a.RequestID,
+		CreatedAt:      a.CreatedAt,
+	}
+}
+
+func (s BudgetStatus) Exceeded() bool {
+	return s.Spend > s.MonthlyLimit
+}
+
+func (s BudgetStatus) ToResponse() *BudgetStatusResponse {
+	return &BudgetStatusResponse{
+		BudgetResponse: *s.Budget.ToResponse(),
+		Month:          formatDate(s.Month),
+		Spend:          s.Spend,
+		Remaining:      s.MonthlyLimit - s.Spend,
+		Exceeded:       s.Exceeded(),
+	}
+}
+
+func (s BudgetStatus) Warning() string {
+	return fmt.Sprintf("Budget %d is exceeded for %s: spend %d of %d", s.ID, formatDate(s.Month), s.Spend, s.MonthlyLimit)
+}
+
+// ParseMonth parses month in any supported format or month of YYYY-MM-DD date, empty month means current one
+func ParseMonth(month string) (time.Time, error) {
+	if month == "" {
+		now := time.Now().UTC()
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+
+	date, err := parseDate(month)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+// BudgetCheckMonths returns months whose spend is affected by subscription record, from the first to the last
+// month of its period, the current month being the last one of open-ended record. Only the latest
+// MaxBudgetCheckMonths of them are returned
+func (sub Subscription) BudgetCheckMonths() []time.Time {
+	first := time.Date(sub.StartDate.Year(), sub.StartDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	last, _ := ParseMonth("")
+	if sub.EndDate != nil {
+		last = time.Date(sub.EndDate.Year(), sub.EndDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	if last.Before(first) {
+		last = first
+	}
+
+	if horizon := last.AddDate(0, 1-MaxBudgetCheckMonths, 0); first.Before(horizon) {
+		first = horizon
+	}
+
+	var months []time.Time
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		months = append(months, month)
+	}
+
+	return months
+}
+
+// BudgetWarnings describes budgets the last write of subscription record pushed over their limit
+func (sub Subscription) BudgetWarnings() []string {
+	var warnings []string
+	for _, status := range sub.ExceededBudgets {
+		warnings = append(warnings, status.Warning())
+	}
+
+	return warnings
+}
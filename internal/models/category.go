@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// UncategorizedCategory is category of services nobody has assigned category to yet
+const UncategorizedCategory = "uncategorized"
+
+const CostGroupByCategory = "category"
+
+type ServiceCategory struct {
+	ServiceName   string
+	Category      string
+	Subscriptions int
+}
+
+// @Description Request to assign category to service
+type ServiceCategoryRequest struct {
+	// @Description Category of service, e.g. music, video, cloud or productivity
+	// @Example music
+	Category string `json:"category"`
+}
+
+// @Description Service with its category
+type ServiceCategoryResponse struct {
+	// @Description Name of the service
+	// @Example Yandex Plus
+	ServiceName string `json:"service_name"`
+
+	// @Description Category of service, uncategorized if none was assigned
+	// @Example music
+	Category string `json:"category"`
+
+	// @Description Number of subscription records of the service
+	// @Example 12
+	Subscriptions int `json:"subscriptions"`
+}
+
+// NormalizeCategory makes category names case insensitive, so that Music and music are the same category
+func NormalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+func (req ServiceCategoryRequest) ToCategory() (string, error) {
+	category := NormalizeCategory(req.Category)
+	if category == "" {
+		return "", errors.New("category must not be empty")
+	}
+
+	if category == UncategorizedCategory {
+		return "", errors.New("uncategorized is reserved for services without category, delete category instead")
+	}
+
+	return category, nil
+}
+
+func (c ServiceCategory) ToResponse() ServiceCategoryResponse {
+	return ServiceCategoryResponse{
+		ServiceName:   c.ServiceName,
+		Category:      c.Category,
+		Subscriptions: c.Subscriptions,
+	}
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFormat is format dates of subscription records are written in responses
+type DateFormat string
+
+const (
+	DateFormatMonth      DateFormat = "MM-YYYY"
+	DateFormatISOMonth   DateFormat = "YYYY-MM"
+	DateFormatSlashMonth DateFormat = "MM/YYYY"
+	DateFormatDotMonth   DateFormat = "MM.YYYY"
+	DateFormatDay        DateFormat = "YYYY-MM-DD"
+)
+
+// dateLayouts maps supported formats to layouts of time package
+var dateLayouts = map[DateFormat]string{
+	DateFormatMonth:      "01-2006",
+	DateFormatISOMonth:   "2006-01",
+	DateFormatSlashMonth: "01/2006",
+	DateFormatDotMonth:   "01.2006",
+	DateFormatDay:        time.DateOnly,
+}
+
+// dateFormatAliases are short names of formats accepted along with the formats themselves
+var dateFormatAliases = map[string]DateFormat{
+	"month": DateFormatMonth,
+	"day":   DateFormatDay,
+}
+
+// monthNames maps lowercase English and Russian names of months, full and short, to months
+var monthNames = func() map[string]time.Month {
+	names := make(map[string]time.Month)
+	russian := []string{"январь", "февраль", "март", "апрель", "май", "июнь", "июль", "август", "сентябрь", "октябрь", "ноябрь", "декабрь"}
+
+	for month := time.January; month <= time.December; month++ {
+		english := strings.ToLower(month.String())
+		names[english] = month
+		names[english[:3]] = month
+		names[russian[month-1]] = month
+	}
+
+	return names
+}()
+
+const dateFormatError = "Invalid date format, should be like MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY, July 2025 or YYYY-MM-DD"
+
+// ParseDateFormat parses format of dates in responses, fallback if format is empty
+func ParseDateFormat(format string, fallback DateFormat) (DateFormat, error) {
+	if format == "" {
+		return fallback, nil
+	}
+
+	if alias, exists := dateFormatAliases[strings.ToLower(format)]; exists {
+		return alias, nil
+	}
+
+	dateFormat := DateFormat(strings.ToUpper(format))
+	if _, exists := dateLayouts[dateFormat]; !exists {
+		return "", errors.New("date_format must be MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or YYYY-MM-DD")
+	}
+
+	return dateFormat, nil
+}
+
+// Format writes date in format, MM-YYYY if format is not set
+func (f DateFormat) Format(date time.Time) string {
+	layout, exists := dateLayouts[f]
+	if !exists {
+		return formatDate(date)
+	}
+
+	return date.Format(layout)
+}
+
+// FormatMonth writes month of date in format, formats with day write it as YYYY-MM
+func (f DateFormat) FormatMonth(date time.Time) string {
+	if f == DateFormatDay {
+		return DateFormatISOMonth.Format(date)
+	}
+
+	return f.Format(date)
+}
+
+func formatDate(date time.Time) string {
+	return fmt.Sprintf("%02d-%04d", date.Month(), date.Year())
+}
+
+// parseDate parses date with day or month, which means its first day
+func parseDate(date string) (time.Time, error) {
+	parsed, _, err := parseDatePrecision(date)
+	return parsed, err
+}
+
+// parseEndDate parses the last day of period, so month without day means its last day
+func parseEndDate(date string) (time.Time, error) {
+	end, withDay, err := parseDatePrecision(date)
+	if err != nil || end.IsZero() || withDay {
+		return end, err
+	}
+
+	return end.AddDate(0, 1, -1), nil
+}
+
+// parseDatePrecision parses YYYY-MM-DD date or month in any of supported formats, reporting whether
+// date has day. Empty date is zero time
+func parseDatePrecision(date string) (time.Time, bool, error) {
+	date = strings.TrimSpace(date)
+	if date == "" {
+		return time.Time{}, false, nil
+	}
+
+	if strings.Count(date, "-") == 2 {
+		day, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			return time.Time{}, false, errors.New(dateFormatError)
+		}
+		return day, true, nil
+	}
+
+	year, month, err := splitMonth(date)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if month < 1 || month > 12 {
+		return time.Time{}, false, errors.New("Invalid month, must be from 01 to 12")
+	}
+
+	if year < 1 || year > 9999 {
+		return time.Time{}, false, errors.New("Invalid year, must be from 0001 to 9999")
+	}
+
+	return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC), false, nil
+}
+
+// splitMonth reads year and month number from MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY or month name
+// followed by year. YYYY-MM is told from MM-YYYY by length of its first part
+func splitMonth(date string) (int, int, error) {
+	if fields := strings.Fields(date); len(fields) == 2 {
+		month, exists := monthNames[strings.ToLower(strings.TrimSuffix(fields[0], "."))]
+		year, err := strconv.Atoi(fields[1])
+		if !exists || err != nil {
+			return 0, 0, errors.New(dateFormatError)
+		}
+		return year, int(month), nil
+	}
+
+	for _, separator := range []string{"-", "/", "."} {
+		parts := strings.Split(date, separator)
+		if len(parts) != 2 {
+			continue
+		}
+
+		monthPart, yearPart := parts[0], parts[1]
+		if separator == "-" && len(parts[0]) == 4 {
+			monthPart, yearPart = parts[1], parts[0]
+		}
+
+		if len(monthPart) > 2 || len(yearPart) != 4 {
+			return 0, 0, errors.New(dateFormatError)
+		}
+
+		month, err := strconv.Atoi(monthPart)
+		if err != nil {
+			return 0, 0, errors.New(dateFormatError)
+		}
+		year, err := strconv.Atoi(yearPart)
+		if err != nil {
+			return 0, 0, errors.New(dateFormatError)
+		}
+
+		return year, month, nil
+	}
+
+	return 0, 0, errors.New(dateFormatError)
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	EventSubscriptionCreated = "subscription.created"
+	EventSubscriptionUpdated = "subscription.updated"
+	EventSubscriptionEnded   = "subscription.ended"
+	EventSubscriptionDeleted = "subscription.deleted"
+
+	EventBudgetExceeded = "budget.exceeded"
+)
+
+// Event is a domain event stored in outbox, Sequence grows in order events were committed
+type Event struct {
+	Sequence       int64           `json:"sequence"`
+	Type           string          `json:"type"`
+	SubscriptionID int             `json:"subscription_id"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type EventPayload struct {
+	Actor     string          `json:"actor"`
+	RequestID string          `json:"request_id"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+}
+
+// IsSubscriptionChange reports whether event describes change of subscription record state,
+// other events are only published to outbox sinks
+func (e Event) IsSubscriptionChange() bool {
+	return e.Type != EventBudgetExceeded
+}
+
+// Matches reports whether event concerns subscription record of given user and service,
+// empty filter values match anything. Both states are checked, so moving record out of filter is also seen.
+// Events other than subscription record changes never match
+func (e Event) Matches(userID, serviceName string) bool {
+	if !e.IsSubscriptionChange() {
+		return false
+	}
+
+	if userID == "" && serviceName == "" {
+		return true
+	}
+
+	var payload EventPayload
+	if err := json.Unmarshal(e.Payload, &payload); err != nil {
+		return false
+	}
+
+	for _, snapshot := range []json.RawMessage{payload.Before, payload.After} {
+		if snapshot == nil {
+			continue
+		}
+
+		var record struct {
+			UserID      string `json:"user_id"`
+			ServiceName string `json:"service_name"`
+		}
+		if err := json.Unmarshal(snapshot, &record); err != nil {
+			continue
+		}
+
+		if (userID == "" || record.UserID == userID) && (serviceName == "" || record.ServiceName == serviceName) {
+			return true
+		}
+	}
+
+	return false
+}
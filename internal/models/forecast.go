@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+const MaxForecastMonths = 60
+
+type ForecastMonth struct {
+	Month    time.Time
+	Total    int
+	Services []SubscriptionCostItem
+}
+
+// @Description Projected cost of a single month
+type ForecastMonthResponse struct {
+	// @Description Month and year, format: MM-YYYY
+	// @Example 08-2025
+	Month string `json:"month"`
+
+	// @Description Integer projected total cost of the month
+	// @Example 1197
+	Total int `json:"total"`
+
+	// @Description Projected cost per service, most expensive first
+	Services []SubscriptionCostItemResponse `json:"services"`
+}
+
+// @Description Response with month-by-month spend forecast
+type ForecastResponse struct {
+	// @Description Projected months, starting with the next one
+	Months []ForecastMonthResponse `json:"months"`
+}
+
+// ParseForecastMonths parses number of months to forecast, 12 by default
+func ParseForecastMonths(months string) (int, error) {
+	if months == "" {
+		return 12, nil
+	}
+
+	number, err := strconv.Atoi(months)
+	if err != nil || number < 1 || number > MaxForecastMonths {
+		return 0, errors.New("months must be integer from 1 to 60")
+	}
+
+	return number, nil
+}
+
+func (f ForecastMonth) ToResponse() ForecastMonthResponse {
+	resp := ForecastMonthResponse{
+		Month:    formatDate(f.Month),
+		Total:    f.Total,
+		Services: make([]SubscriptionCostItemResponse, 0, len(f.Services)),
+	}
+
+	for _, service := range f.Services {
+		resp.Services = append(resp.Services, SubscriptionCostItemResponse{Key: service.Key, Cost: service.Cost})
+	}
+
+	return resp
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var csvColumns = []string{"service_name", "price", "user_id", "start_date", "end_date", "billing_day", "tags", "cost_center"}
+
+// CSVHeader maps known column names to their positions in CSV record
+type CSVHeader map[string]int
+
+func ParseCSVHeader(record []string) (CSVHeader, error) {
+	header := make(CSVHeader)
+	for i, column := range record {
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		for _, known := range csvColumns {
+			if name == known {
+				if _, exists := header[name]; exists {
+					return nil, fmt.Errorf("Duplicate column %s in CSV header", name)
+				}
+				header[name] = i
+			}
+		}
+	}
+
+	for _, required := range csvColumns[:4] {
+		if _, exists := header[required]; !exists {
+			return nil, fmt.Errorf("CSV header must contain column %s", required)
+		}
+	}
+
+	return header, nil
+}
+
+func (h CSVHeader) ToSubscriptionRequest(record []string) (*SubscriptionRequest, error) {
+	value := func(column string) string {
+		i, exists := h[column]
+		if !exists || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	price, err := strconv.Atoi(value("price"))
+	if err != nil {
+		return nil, errors.New("Invalid price, must be integer number of rubles")
+	}
+
+	req := SubscriptionRequest{
+		ServiceName: value("service_name"),
+		Price:       price,
+		UserID:      value("user_id"),
+		StartDate:   value("start_date"),
+	}
+
+	if endDate := value("end_date"); endDate != "" {
+		req.EndDate = &endDate
+	}
+
+	if billingDay := value("billing_day"); billingDay != "" {
+		day, err := strconv.Atoi(billingDay)
+		if err != nil {
+			return nil, errors.New("Invalid billing_day, must be integer day of month")
+		}
+		req.BillingDay = &day
+	}
+
+	if tags := value("tags"); tags != "" {
+		req.Tags = strings.Split(tags, ";")
+	}
+
+	if costCenter := value("cost_center"); costCenter != "" {
+		req.CostCenter = &costCenter
+	}
+
+	if req.ServiceName == "" {
+		return nil, errors.New("service_name must not be empty")
+	}
+
+	if req.UserID == "" {
+		return nil, errors.New("user_id must not be empty")
+	}
+
+	if req.StartDate == "" {
+		return nil, errors.New("start_date must not be empty")
+	}
+
+	return &req, nil
+}
+
+// @Description Validation error of single CSV row
+type ImportRowError struct {
+	// @Description Number of row in CSV file, header is row 1
+	// @Example 2
+	Row int `json:"row"`
+
+	// @Description Reason why row was rejected
+	// @Example Invalid date format, should be like MM-YYYY
+	Error string `json:"error"`
+}
+
+// @Description Response with results of CSV import
+type ImportResponse struct {
+	// @Description Whether import only validated rows without writing them
+	// @Example false
+	DryRun bool `json:"dry_run"`
+
+	// @Description Number of processed data rows
+	// @Example 3
+	Rows int `json:"rows"`
+
+	// @Description Number of created subscription records, or records that would be created in dry run
+	// @Example 1
+	Created int `json:"created"`
+
+	// @Description Number of rows skipped as duplicates of existing records or of previous rows
+	// @Example 1
+	Duplicates int `json:"duplicates"`
+
+	// @Description Whether dry run had too many rows to detect all duplicates of previous rows, so real import may skip more
+	// @Example false
+	DuplicatesIncomplete bool `json:"duplicates_incomplete,omitempty"`
+
+	// @Description Number of rejected rows
+	// @Example 1
+	Failed int `json:"failed"`
+
+	// @Description Row-level errors, at most first 1000 are reported
+	Errors []ImportRowError `json:"errors"`
+
+	// @Description Whether some row-level errors were omitted from response
+	// @Example false
+	ErrorsTruncated bool `json:"errors_truncated"`
+
+	// @Description Error that stopped import, records counted as created are saved, rows after the last saved chunk are not
+	// @Example Failed to check duplicates
+	Error string `json:"error,omitempty"`
+}
+
+const maxImportErrors = 1000
+
+func (resp *ImportResponse) AddError(row int, err error) {
+	resp.Failed++
+	if len(resp.Errors) >= maxImportErrors {
+		resp.ErrorsTruncated = true
+		return
+	}
+	resp.Errors = append(resp.Errors, ImportRowError{Row: row, Error: err.Error()})
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// SubscriptionMember is a user sharing cost of subscription record. Every member pays
+// weight divided by sum of weights of all members of the record
+type SubscriptionMember struct {
+	UserID uuid.UUID `json:"user_id"`
+	Weight int       `json:"weight"`
+}
+
+// @Description Member sharing cost of subscription
+type SubscriptionMemberRequest struct {
+	// @Description Member's UUID
+	// @Example 60601fee-2bf1-4721-ae6f-7636e79a0cba
+	UserID string `json:"user_id"`
+
+	// @Description Positive integer weight of member's share, 1 if absent, so equal weights split cost equally
+	// @Example 1
+	Weight int `json:"weight"`
+}
+
+// @Description Member sharing cost of subscription
+type SubscriptionMemberResponse struct {
+	// @Description Member's UUID
+	// @Example 60601fee-2bf1-4721-ae6f-7636e79a0cba
+	UserID string `json:"user_id"`
+
+	// @Description Integer weight of member's share
+	// @Example 1
+	Weight int `json:"weight"`
+
+	// @Description Part of price member pays
+	// @Example 0.5
+	Share float64 `json:"share"`
+}
+
+func toSubscriptionMembers(requests []SubscriptionMemberRequest) ([]SubscriptionMember, error) {
+	members := make([]SubscriptionMember, 0, len(requests))
+	seen := make(map[uuid.UUID]struct{}, len(requests))
+
+	for _, req := range requests {
+		userID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid member user_id format, must be uuid: %v", err)
+		}
+
+		if _, exists := seen[userID]; exists {
+			return nil, errors.New("Members must not repeat")
+		}
+		seen[userID] = struct{}{}
+
+		weight := req.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		if weight < 0 {
+			return nil, errors.New("Member weight must be positive")
+		}
+
+		members = append(members, SubscriptionMember{UserID: userID, Weight: weight})
+	}
+
+	return members, nil
+}
+
+// ShareOf returns part of price user pays: the whole price for the owner of record without members,
+// weighted share for a member of shared record and nothing otherwise
+func (sub Subscription) ShareOf(userID uuid.UUID) float64 {
+	if len(sub.Members) == 0 {
+		if sub.UserID == userID {
+			return 1
+		}
+		return 0
+	}
+
+	total, weight := 0, 0
+	for _, member := range sub.Members {
+		total += member.Weight
+		if member.UserID == userID {
+			weight = member.Weight
+		}
+	}
+
+	return float64(weight) / float64(total)
+}
+
+// SameMembers reports whether records are shared between the same members with the same weights
+func (sub Subscription) SameMembers(other *Subscription) bool {
+	if len(sub.Members) != len(other.Members) {
+		return false
+	}
+
+	weights := make(map[uuid.UUID]int, len(sub.Members))
+	for _, member := range sub.Members {
+		weights[member.UserID] = member.Weight
+	}
+
+	for _, member := range other.Members {
+		if weight, exists := weights[member.UserID]; !exists || weight != member.Weight {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (sub Subscription) membersResponse() []SubscriptionMemberResponse {
+	if len(sub.Members) == 0 {
+		return nil
+	}
+
+	members := make([]SubscriptionMemberResponse, 0, len(sub.Members))
+	for _, member := range sub.Members {
+		members = append(members, SubscriptionMemberResponse{
+			UserID: member.UserID.String(),
+			Weight: member.Weight,
+			Share:  math.Round(sub.ShareOf(member.UserID)*10000) / 10000,
+		})
+	}
+
+	return members
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// @Description Request to merge subscription records
+type MergeRequest struct {
+	// @Description IDs of subscription records of the same user, service, price and billing day to merge
+	// @Example [1, 2]
+	IDs []int `json:"ids"`
+}
+
+// @Description Request to split subscription record
+type SplitRequest struct {
+	// @Description Date the second part starts from, format: MM-YYYY or YYYY-MM-DD, month means its first day
+	// @Example 09-2025
+	Month string `json:"month"`
+
+	// @Description New price of the second part, the same as original if absent
+	// @Example 499
+	Price *int `json:"price"`
+}
+
+func (req MergeRequest) Validate() error {
+	if len(req.IDs) < 2 {
+		return errors.New("At least two ids are required to merge")
+	}
+
+	seen := make(map[int]struct{}, len(req.IDs))
+	for _, id := range req.IDs {
+		if _, exists := seen[id]; exists {
+			return errors.New("ids must not repeat")
+		}
+		seen[id] = struct{}{}
+	}
+
+	return nil
+}
+
+func (req SplitRequest) ToDate() (time.Time, error) {
+	if req.Month == "" {
+		return time.Time{}, errors.New("month is required")
+	}
+
+	if req.Price != nil && *req.Price < 0 {
+		return time.Time{}, errors.New("price must not be negative")
+	}
+
+	return parseDate(req.Month)
+}
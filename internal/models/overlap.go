@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Overlap struct {
+	FirstID       int
+	SecondID      int
+	UserID        uuid.UUID
+	ServiceName   string
+	StartDate     time.Time
+	EndDate       *time.Time
+	Months        int
+	DoubleCharged int
+}
+
+// @Description Pair of subscription records of the same user and service with overlapping periods
+type OverlapResponse struct {
+	// @Description Integer ID of the earlier created subscription record
+	// @Example 1
+	FirstID int `json:"first_id"`
+
+	// @Description Integer ID of the later created subscription record
+	// @Example 2
+	SecondID int `json:"second_id"`
+
+	// @Description User's UUID
+	// @Example 60601fee-2bf1-4721-ae6f-7636e79a0cba
+	UserID string `json:"user_id"`
+
+	// @Description Service Name
+	// @Example Yandex Plus
+	ServiceName string `json:"service_name"`
+
+	// @Description Month and year overlap starts, format: MM-YYYY
+	// @Example 07-2025
+	StartDate string `json:"start_date"`
+
+	// @Description Month and year overlap ends, format: MM-YYYY. Absent when both records are open-ended
+	// @Example 08-2025
+	EndDate *string `json:"end_date"`
+
+	// @Description Number of charges made twice up to now or to the end of overlap: billing dates within the overlap of the record charged less often
+	// @Example 2
+	Months int `json:"months"`
+
+	// @Description Amount charged twice: charges made twice multiplied by the lower price
+	// @Example 798
+	DoubleCharged int `json:"double_charged"`
+}
+
+func (o Overlap) ToResponse() *OverlapResponse {
+	resp := OverlapResponse{
+		FirstID:       o.FirstID,
+		SecondID:      o.SecondID,
+		UserID:        o.UserID.String(),
+		ServiceName:   o.ServiceName,
+		StartDate:     formatDate(o.StartDate),
+		Months:        o.Months,
+		DoubleCharged: o.DoubleCharged,
+	}
+
+	if o.EndDate != nil {
+		temp := formatDate(*o.EndDate)
+		resp.EndDate = &temp
+	}
+
+	return &resp
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	SubscriptionStatusActive = "active"
+	SubscriptionStatusEnded  = "ended"
+)
+
+// MaxRenewalRunsLimit is the largest number of renewal runs listed at once
+const MaxRenewalRunsLimit = 100
+
+type RenewalRun struct {
+	ID             int
+	StartedAt      time.Time
+	FinishedAt     time.Time
+	Ended          int
+	Renewed        int
+	ConflictingIDs []int64 // records ended instead of extended, as extension overlapped another record
+	Error          *string
+}
+
+// @Description Outcome of a run of subscription expiry and renewal job
+type RenewalRunResponse struct {
+	// @Description Run ID
+	// @Example 1
+	ID int `json:"id"`
+
+	// @Description Moment run started at
+	// @Example 2025-07-01T00:00:00Z
+	StartedAt time.Time `json:"started_at"`
+
+	// @Description Moment run finished at
+	// @Example 2025-07-01T00:00:01Z
+	FinishedAt time.Time `json:"finished_at"`
+
+	// @Description Number of subscription records marked as ended
+	// @Example 3
+	Ended int `json:"ended"`
+
+	// @Description Number of subscription records extended by one billing period
+	// @Example 2
+	Renewed int `json:"renewed"`
+
+	// @Description IDs of auto-renewing subscription records not extended because extension would overlap another record
+	// @Description of the same user and service, their auto-renew is turned off and they are marked as ended
+	// @Example [7]
+	ConflictingIDs []int64 `json:"conflicting_ids"`
+
+	// @Description Error the run failed with, nothing is changed by failed run
+	// @Example context deadline exceeded
+	Error *string `json:"error"`
+}
+
+func (run RenewalRun) ToResponse() *RenewalRunResponse {
+	return &RenewalRunResponse{
+		ID:             run.ID,
+		StartedAt:      run.StartedAt,
+		FinishedAt:     run.FinishedAt,
+		Ended:          run.Ended,
+		Renewed:        run.Renewed,
+		ConflictingIDs: run.ConflictingIDs,
+		Error:          run.Error,
+	}
+}
+
+// ParseRenewalRunsLimit parses number of the latest renewal runs to list, 20 by default
+func ParseRenewalRunsLimit(limit string) (int, error) {
+	if limit == "" {
+		return 20, nil
+	}
+
+	number, err := strconv.Atoi(limit)
+	if err != nil || number < 1 || number > MaxRenewalRunsLimit {
+		return 0, errors.New("limit must be integer from 1 to 100")
+	}
+
+	return number, nil
+}
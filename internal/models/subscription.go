@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -18,6 +17,15 @@ type Subscription struct {
 	UserID      uuid.UUID  `json:"user_id"`
 	StartDate   time.Time  `json:"start_date"`
 	EndDate     *time.Time `json:"end_date,omitempty"`
+	BillingDay  int        `json:"billing_day"`
+	Members     []SubscriptionMember `json:"members,omitempty"`
+	Tags        []string   `json:"tags"`
+	CostCenter  *string    `json:"cost_center,omitempty"`
+	Status      string     `json:"status"`
+	AutoRenew   bool       `json:"auto_renew"`
+
+	// ExceededBudgets are budgets the last write of the record pushed over their limit
+	ExceededBudgets []*BudgetStatus `json:"-"`
 }
 
 // @Description Request to create or update subscription record
@@ -34,13 +42,32 @@ type SubscriptionRequest struct {
 	// @Example 60601fee-2bf1-4721-ae6f-7636e79a0cba
 	UserID      string  `json:"user_id"`
 
-	// @Description Month and year of subscription srart, format: MM-YYYY
+	// @Description Subscription start, format: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY, month name with year or YYYY-MM-DD, month means its first day
 	// @Example 07-2025
 	StartDate   string  `json:"start_date"`
 
-	// @Description Month and year of subsription end, format: MM-YYYY
+	// @Description Last day of subscription, format: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY, month name with year or YYYY-MM-DD, month means its last day
 	// @Example 08-2025
 	EndDate     *string `json:"end_date"`
+
+	// @Description Day of month subscription is charged on, the last day in shorter months. Day of start by default
+	// @Example 17
+	BillingDay  *int    `json:"billing_day"`
+
+	// @Description Extend subscription by one billing period when it ends. Absent keeps current value on partial update
+	// @Example true
+	AutoRenew   *bool   `json:"auto_renew"`
+
+	// @Description Members sharing cost of subscription, the owner pays the whole price if empty. Absent keeps current members on update
+	Members     []SubscriptionMemberRequest `json:"members"`
+
+	// @Description Free-form tags, e.g. projects the subscription is used for
+	// @Example ["project-apollo", "design"]
+	Tags        []string `json:"tags"`
+
+	// @Description Cost center the subscription is paid from, empty string clears it on partial update
+	// @Example marketing
+	CostCenter  *string `json:"cost_center"`
 }
 
 // @Description Response with information about subscription
@@ -61,13 +88,40 @@ type SubscriptionResponse struct {
 	// @Example 60601fee-2bf1-4721-ae6f-7636e79a0cba
 	UserID      string  `json:"user_id"`
 
-	// @Description Month and year of subscription srart, format: MM-YYYY
+	// @Description Subscription start, in requested format, MM-YYYY by default
 	// @Example 07-2025
 	StartDate   string  `json:"start_date"`
 
-	// @Description Month and year of subsription end, format: MM-YYYY
+	// @Description Last day of subscription, in requested format, MM-YYYY by default
 	// @Example 08-2025
 	EndDate     *string `json:"end_date"`
+
+	// @Description Day of month subscription is charged on
+	// @Example 1
+	BillingDay  int     `json:"billing_day"`
+
+	// @Description Status of subscription: active or ended once its end date has passed
+	// @Example active
+	Status      string  `json:"status"`
+
+	// @Description Whether subscription is extended by one billing period when it ends
+	// @Example false
+	AutoRenew   bool    `json:"auto_renew"`
+
+	// @Description Members sharing cost of subscription, absent if the owner pays the whole price
+	Members     []SubscriptionMemberResponse `json:"members,omitempty"`
+
+	// @Description Free-form tags
+	// @Example ["project-apollo", "design"]
+	Tags        []string `json:"tags"`
+
+	// @Description Cost center the subscription is paid from
+	// @Example marketing
+	CostCenter  *string `json:"cost_center"`
+
+	// @Description Warnings about the change, e.g. exceeded budgets
+	// @Example ["Budget 1 is exceeded for 07-2025: spend 1200 of 1000"]
+	Warnings    []string `json:"warnings,omitempty"`
 }
 
 // @Description Request with parameters to calculate cost of subscription records
@@ -80,20 +134,59 @@ type SubscriptionCostRequest struct {
 	// @Example 60601fee-2bf1-4721-ae6f-7636e79a0cba
 	UserID      string `json:"user_id"`
 
-	// @Description Month and year of subscription srart, format: MM-YYYY
+	// @Description First day of window, format: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY, month name with year or YYYY-MM-DD, month means its first day
 	// @Example 07-2025
 	StartDate   string `json:"start_date"`
 
-	// @Description Month and year of subsription end, format: MM-YYYY
+	// @Description Last day of window, format: MM-YYYY, YYYY-MM, MM/YYYY, MM.YYYY, month name with year or YYYY-MM-DD, month means its last day
 	// @Example 08-2025
 	EndDate     string `json:"end_date"`
+
+	// @Description Moment in RFC 3339 format to calculate cost as data stood at
+	// @Example 2025-07-01T00:00:00Z
+	AsOf        string `json:"as_of"`
+
+	// @Description Category of services
+	// @Example music
+	Category    string `json:"category"`
 }
 
 type SubscriptionCost struct {
 	ServiceName sql.NullString `json:"service_name"`
+	Category    sql.NullString `json:"category"`
 	UserID      *uuid.UUID     `json:"user_id"`
 	StartDate   *time.Time     `json:"start_date"`
 	EndDate     *time.Time     `json:"end_date"`
+	AsOf        *time.Time     `json:"as_of"`
+	IDs         []int          `json:"ids"`
+}
+
+// @Description Request with parameters to filter listed subscription records
+type SubscriptionFilterRequest struct {
+	// @Description Moment in RFC 3339 format to list subscription records as they stood at
+	// @Example 2025-07-01T00:00:00Z
+	AsOf string `json:"as_of"`
+
+	// @Description Tags every listed record must be marked with
+	// @Example ["project-apollo"]
+	Tags []string `json:"tags"`
+
+	// @Description Cost center of listed records
+	// @Example marketing
+	CostCenter string `json:"cost_center"`
+}
+
+type SubscriptionFilter struct {
+	AsOf           *time.Time
+	UserID         *uuid.UUID
+	UserIDs        []uuid.UUID
+	ActiveAt       *time.Time // records active in the month
+	NotEndedBefore *time.Time // records not ended before the date, including ones starting after it
+	Tags           []string
+	CostCenter     *string
+	AfterID        int
+	Limit          int
+	DateFormat     DateFormat
 }
 
 // @Description Response with total cost of subscription records
@@ -101,6 +194,9 @@ type SubscriptionCostResponse struct {
 	// @Description Integer total cost of subscription records
 	// @Exmaple 2344
 	Cost int `json:"cost"`
+
+	// @Description Total cost per service category, most expensive first
+	Categories []SubscriptionCostItemResponse `json:"categories"`
 }
 
 func (s SubscriptionCost) String() string {
@@ -124,6 +220,11 @@ func (req SubscriptionCostRequest) ToSubscriptionCost() (*SubscriptionCost, erro
 		subscription.UserID = &userUUID
 	}
 
+	if category := NormalizeCategory(req.Category); category != "" {
+		subscription.Category.String = category
+		subscription.Category.Valid = true
+	}
+
 	if req.StartDate != "" {
 		startDate, err := parseDate(req.StartDate)
 		if err != nil {
@@ -136,7 +237,7 @@ func (req SubscriptionCostRequest) ToSubscriptionCost() (*SubscriptionCost, erro
 	}
 
 	if req.EndDate != "" {
-		endDate, err := parseDate(req.EndDate)
+		endDate, err := parseEndDate(req.EndDate)
 		if err != nil {
 			return nil, err
 		}
@@ -146,20 +247,60 @@ func (req SubscriptionCostRequest) ToSubscriptionCost() (*SubscriptionCost, erro
 		}
 	}
 
+	asOf, err := parseAsOf(req.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	subscription.AsOf = asOf
+
 	return &subscription, nil
 }
 
+func (req SubscriptionFilterRequest) ToSubscriptionFilter() (*SubscriptionFilter, error) {
+	asOf, err := parseAsOf(req.AsOf)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &SubscriptionFilter{AsOf: asOf}
+
+	if tags := NormalizeTags(req.Tags); len(tags) > 0 {
+		filter.Tags = tags
+	}
+
+	if req.CostCenter != "" {
+		filter.CostCenter = &req.CostCenter
+	}
+
+	return filter, nil
+}
+
 func (sub Subscription) ToResponse() *SubscriptionResponse {
+	return sub.ToResponseIn(DateFormatMonth)
+}
+
+// ToResponseIn makes response with dates in format
+func (sub Subscription) ToResponseIn(format DateFormat) *SubscriptionResponse {
 	resp := SubscriptionResponse{
 		ID:          sub.ID,
 		ServiceName: sub.ServiceName,
 		Price:       sub.Price,
 		UserID:      sub.UserID.String(),
-		StartDate:   formatDate(sub.StartDate),
+		StartDate:   format.Format(sub.StartDate),
+		BillingDay:  sub.BillingDay,
+		Status:      sub.Status,
+		AutoRenew:   sub.AutoRenew,
+		Members:     sub.membersResponse(),
+		Tags:        sub.Tags,
+		CostCenter:  sub.CostCenter,
+	}
+
+	if resp.Tags == nil {
+		resp.Tags = []string{}
 	}
 
 	if sub.EndDate != nil {
-		temp := formatDate(*sub.EndDate)
+		temp := format.Format(*sub.EndDate)
 		resp.EndDate = &temp
 	}
 
@@ -174,7 +315,7 @@ func (req SubscriptionRequest) ToSubscription() (*Subscription, error) {
 
 	var endDate time.Time
 	if req.EndDate != nil {
-		endDate, err = parseDate(*req.EndDate)
+		endDate, err = parseEndDate(*req.EndDate)
 		if err != nil {
 			return nil, err
 		}
@@ -186,6 +327,17 @@ func (req SubscriptionRequest) ToSubscription() (*Subscription, error) {
 		StartDate:   stardDate,
 	}
 
+	if req.BillingDay != nil {
+		if *req.BillingDay < 1 || *req.BillingDay > 31 {
+			return nil, errors.New("billing_day must be from 1 to 31")
+		}
+		subscription.BillingDay = *req.BillingDay
+	}
+
+	if req.AutoRenew != nil {
+		subscription.AutoRenew = *req.AutoRenew
+	}
+
 	if endDate.IsZero() {
 		subscription.EndDate = nil
 	} else {
@@ -200,31 +352,120 @@ func (req SubscriptionRequest) ToSubscription() (*Subscription, error) {
 		subscription.UserID = userUUID
 	}
 
+	if req.Tags != nil {
+		subscription.Tags = NormalizeTags(req.Tags)
+	}
+
+	if req.CostCenter != nil {
+		costCenter := strings.TrimSpace(*req.CostCenter)
+		subscription.CostCenter = &costCenter
+	}
+
+	if req.Members != nil {
+		members, err := toSubscriptionMembers(req.Members)
+		if err != nil {
+			return nil, err
+		}
+		subscription.Members = members
+	}
+
 	return &subscription, nil
 }
 
-func parseDate(date string) (time.Time, error) {
-	if date == "" {
-		return time.Time{}, nil
+// Patch fills fields of subscription record made from partial update request req with fields of old record
+// absent in the request
+func (sub *Subscription) Patch(old *Subscription, req SubscriptionRequest) {
+	if sub.ServiceName == "" {
+		sub.ServiceName = old.ServiceName
 	}
 
-	parts := strings.Split(date, "-")
-	if len(parts) != 2 {
-		return time.Time{}, errors.New("Invalid date format, should be like MM-YYYY")
+	if sub.Price == 0 {
+		sub.Price = old.Price
 	}
 
-	year, err := strconv.Atoi(parts[1])
-	if err != nil {
-		return time.Time{}, errors.New("Invalid date format, should be like MM-YYYY")
+	if sub.UserID == uuid.Nil {
+		sub.UserID = old.UserID
+	}
+
+	if sub.StartDate.IsZero() {
+		sub.StartDate = old.StartDate
+
+		if sub.BillingDay == 0 {
+			sub.BillingDay = old.BillingDay
+		}
+	}
+
+	if sub.EndDate == nil {
+		sub.EndDate = old.EndDate
+	}
+
+	if sub.Tags == nil {
+		sub.Tags = old.Tags
+	}
+
+	if sub.CostCenter == nil {
+		sub.CostCenter = old.CostCenter
 	}
-	month, err := strconv.Atoi(parts[0])
+
+	if req.AutoRenew == nil {
+		sub.AutoRenew = old.AutoRenew
+	}
+
+	sub.ID = old.ID
+}
+
+// FirstChargeDate returns the first billing day of subscription not before its start
+func (sub Subscription) FirstChargeDate() time.Time {
+	charge := chargeDateIn(sub.StartDate, sub.BillingDay)
+	if charge.Before(sub.StartDate) {
+		charge = chargeDateIn(sub.StartDate.AddDate(0, 0, 1-sub.StartDate.Day()).AddDate(0, 1, 0), sub.BillingDay)
+	}
+
+	return charge
+}
+
+// chargeDateIn returns billing day in month of date, the last day of month if it is shorter
+func chargeDateIn(date time.Time, billingDay int) time.Time {
+	lastDay := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	return time.Date(date.Year(), date.Month(), min(billingDay, lastDay), 0, 0, 0, 0, time.UTC)
+}
+
+func parseAsOf(asOf string) (*time.Time, error) {
+	if asOf == "" {
+		return nil, nil
+	}
+
+	moment, err := time.Parse(time.RFC3339, asOf)
 	if err != nil {
-		return time.Time{}, errors.New("Invalid date format, should be like MM-YYYY")
+		return nil, fmt.Errorf("Invalid as_of format, must be RFC 3339: %v", err)
 	}
 
-	return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC), nil
+	return &moment, nil
 }
 
-func formatDate(date time.Time) string {
-	return fmt.Sprintf("%02d-%04d", date.Month(), date.Year())
+type SubscriptionCostItem struct {
+	Key  string
+	Cost int
+}
+
+// @Description Cost of subscription records sharing the same value of grouping key
+type SubscriptionCostItemResponse struct {
+	// @Description Value of grouping key
+	// @Example Yandex Plus
+	Key string `json:"key"`
+
+	// @Description Integer total cost of subscription records in group
+	// @Example 798
+	Cost int `json:"cost"`
+}
+
+// @Description Response with cost of subscription records broken down by grouping key
+type SubscriptionCostBreakdownResponse struct {
+	// @Description Field subscription records are grouped by
+	// @Example service_name
+	GroupBy string `json:"group_by"`
+
+	// @Description Cost of every group, most expensive first
+	Items []SubscriptionCostItemResponse `json:"items"`
 }
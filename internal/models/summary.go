@@ -0,0 +1,146 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const MaxEndingWithinMonths = 12
+
+type UserSummary struct {
+	UserID               uuid.UUID
+	Month                time.Time
+	Active               []*Subscription
+	MonthlySpend         int
+	YearSpend            int
+	LifetimeTotal        int
+	MostExpensiveService *SubscriptionCostItem
+	EndingSoon           []*Subscription
+}
+
+// @Description Summary of user subscriptions and spend
+type UserSummaryResponse struct {
+	// @Description User UUID
+	// @Example 60601fee-2bf1-4721-ae6f-7636e79a0cba
+	UserID uuid.UUID `json:"user_id"`
+
+	// @Description Current month summary is computed for, in requested format, MM-YYYY by default
+	// @Example 08-2025
+	Month string `json:"month"`
+
+	// @Description Subscription records active in the current month
+	Active []*SubscriptionResponse `json:"active"`
+
+	// @Description Integer cost of subscriptions active in the current month, user's share for shared ones
+	// @Example 798
+	MonthlySpend int `json:"monthly_spend"`
+
+	// @Description Integer sum of monthly charges from January up to the current month
+	// @Example 6384
+	YearSpend int `json:"year_spend"`
+
+	// @Description Integer sum of all monthly charges up to the current month
+	// @Example 11571
+	LifetimeTotal int `json:"lifetime_total"`
+
+	// @Description Active service with the highest monthly cost, null if there are no active subscriptions
+	MostExpensiveService *SubscriptionCostItemResponse `json:"most_expensive_service"`
+
+	// @Description Active subscription records whose last month is within the window
+	EndingSoon []*SubscriptionResponse `json:"ending_soon"`
+}
+
+// ParseEndingWithin parses number of months after the current one subscriptions ending in
+// are reported as ending soon, 1 by default
+func ParseEndingWithin(months string) (int, error) {
+	if months == "" {
+		return 1, nil
+	}
+
+	number, err := strconv.Atoi(months)
+	if err != nil || number < 0 || number > MaxEndingWithinMonths {
+		return 0, errors.New("ending_within must be integer from 0 to 12")
+	}
+
+	return number, nil
+}
+
+// NewUserSummary derives monthly spend, most expensive service and subscriptions ending within
+// endingWithin months after month from records active in it. Shared records count with user's share
+func NewUserSummary(userID uuid.UUID, month time.Time, active []*Subscription, endingWithin int) *UserSummary {
+	summary := &UserSummary{
+		UserID: userID,
+		Month:  month,
+		Active: active,
+	}
+
+	deadline := month.AddDate(0, endingWithin+1, 0)
+	costs := make(map[string]float64)
+	monthlySpend := 0.0
+
+	for _, subscription := range active {
+		cost := float64(subscription.Price) * subscription.ShareOf(userID)
+		monthlySpend += cost
+		costs[subscription.ServiceName] += cost
+
+		if subscription.EndDate != nil && subscription.EndDate.Before(deadline) {
+			summary.EndingSoon = append(summary.EndingSoon, subscription)
+		}
+	}
+
+	summary.MonthlySpend = int(math.Round(monthlySpend))
+
+	services := make([]string, 0, len(costs))
+	for service := range costs {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	for _, service := range services {
+		cost := int(math.Round(costs[service]))
+		if summary.MostExpensiveService == nil || cost > summary.MostExpensiveService.Cost {
+			summary.MostExpensiveService = &SubscriptionCostItem{Key: service, Cost: cost}
+		}
+	}
+
+	return summary
+}
+
+func (s UserSummary) ToResponse() *UserSummaryResponse {
+	return s.ToResponseIn(DateFormatMonth)
+}
+
+// ToResponseIn makes response with dates of subscription records in format
+func (s UserSummary) ToResponseIn(format DateFormat) *UserSummaryResponse {
+	resp := &UserSummaryResponse{
+		UserID:        s.UserID,
+		Month:         format.FormatMonth(s.Month),
+		Active:        make([]*SubscriptionResponse, 0, len(s.Active)),
+		MonthlySpend:  s.MonthlySpend,
+		YearSpend:     s.YearSpend,
+		LifetimeTotal: s.LifetimeTotal,
+		EndingSoon:    make([]*SubscriptionResponse, 0, len(s.EndingSoon)),
+	}
+
+	for _, subscription := range s.Active {
+		resp.Active = append(resp.Active, subscription.ToResponseIn(format))
+	}
+
+	for _, subscription := range s.EndingSoon {
+		resp.EndingSoon = append(resp.EndingSoon, subscription.ToResponseIn(format))
+	}
+
+	if s.MostExpensiveService != nil {
+		resp.MostExpensiveService = &SubscriptionCostItemResponse{
+			Key:  s.MostExpensiveService.Key,
+			Cost: s.MostExpensiveService.Cost,
+		}
+	}
+
+	return resp
+}
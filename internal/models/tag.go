@@ -0,0 +1,109 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	CostGroupByServiceName = "service_name"
+	CostGroupByCostCenter  = "cost_center"
+	CostGroupByTag         = "tag"
+)
+
+// Groupings used to calculate costs of many records or users in one query, they are not accepted from clients
+const (
+	CostGroupBySubscription = "subscription"
+	CostGroupByUser         = "user"
+)
+
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// @Description Tag with number of subscription records marked with it
+type TagResponse struct {
+	// @Description Tag
+	// @Example project-apollo
+	Tag string `json:"tag"`
+
+	// @Description Number of subscription records marked with tag
+	// @Example 4
+	Count int `json:"count"`
+}
+
+// @Description Request to rename tag or merge several tags into one across all subscription records
+type TagMergeRequest struct {
+	// @Description Tags to replace
+	// @Example ["apollo", "project-apolo"]
+	From []string `json:"from"`
+
+	// @Description Tag to replace them with, may be one of existing tags
+	// @Example project-apollo
+	To string `json:"to"`
+}
+
+// @Description Response with result of tag rename or merge
+type TagMergeResponse struct {
+	// @Description Tag the others were replaced with
+	// @Example project-apollo
+	To string `json:"to"`
+
+	// @Description Number of changed subscription records
+	// @Example 3
+	Updated int `json:"updated"`
+}
+
+// NormalizeTags trims tags and drops empty and repeated ones keeping order
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		if _, exists := seen[tag]; exists {
+			continue
+		}
+		seen[tag] = struct{}{}
+
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+func (req TagMergeRequest) Validate() ([]string, string, error) {
+	from := NormalizeTags(req.From)
+	to := strings.TrimSpace(req.To)
+
+	if len(from) == 0 {
+		return nil, "", errors.New("from must contain at least one tag")
+	}
+
+	if to == "" {
+		return nil, "", errors.New("to must not be empty")
+	}
+
+	return from, to, nil
+}
+
+// ParseCostGroupBy parses field subscription cost is broken down by, service_name by default
+func ParseCostGroupBy(groupBy string) (string, error) {
+	switch groupBy {
+	case "":
+		return CostGroupByServiceName, nil
+	case CostGroupByServiceName, CostGroupByCostCenter, CostGroupByTag, CostGroupByCategory:
+		return groupBy, nil
+	default:
+		return "", errors.New("group_by must be service_name, category, cost_center or tag")
+	}
+}
+
+func (t TagCount) ToResponse() TagResponse {
+	return TagResponse{Tag: t.Tag, Count: t.Count}
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	UserDeleteBlock      = "block"
+	UserDeleteSoftDelete = "soft_delete"
+)
+
+type User struct {
+	ID        uuid.UUID
+	Name      *string
+	Email     *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// @Description Request to create or update user
+type UserRequest struct {
+	// @Description User UUID, generated if absent on creation and ignored on update
+	// @Example 60601fee-2bf1-4721-ae6f-7636e79a0cba
+	ID string `json:"id"`
+
+	// @Description Name of user
+	// @Example Ivan Ivanov
+	Name string `json:"name"`
+
+	// @Description Unique email of user
+	// @Example ivan@example.com
+	Email string `json:"email"`
+}
+
+// @Description Response with information about user
+type UserResponse struct {
+	// @Description User UUID
+	// @Example 60601fee-2bf1-4721-ae6f-7636e79a0cba
+	ID uuid.UUID `json:"id"`
+
+	// @Description Name of user
+	// @Example Ivan Ivanov
+	Name *string `json:"name"`
+
+	// @Description Unique email of user
+	// @Example ivan@example.com
+	Email *string `json:"email"`
+
+	// @Description Moment user was created
+	// @Example 2025-08-01T12:00:00Z
+	CreatedAt time.Time `json:"created_at"`
+
+	// @Description Moment user was last updated
+	// @Example 2025-08-01T12:00:00Z
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (req UserRequest) ToUser() (*User, error) {
+	user := &User{}
+
+	if req.ID != "" {
+		id, err := uuid.Parse(req.ID)
+		if err != nil {
+			return nil, errors.New("Invalid id format, must be uuid")
+		}
+		user.ID = id
+	}
+
+	if req.Name != "" {
+		user.Name = &req.Name
+	}
+
+	if req.Email != "" {
+		if _, err := mail.ParseAddress(req.Email); err != nil {
+			return nil, errors.New("Invalid email format")
+		}
+		user.Email = &req.Email
+	}
+
+	return user, nil
+}
+
+// ParseUserDeleteMode parses what happens to subscription records of removed user, block by default
+func ParseUserDeleteMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return UserDeleteBlock, nil
+	case UserDeleteBlock, UserDeleteSoftDelete:
+		return mode, nil
+	default:
+		return "", errors.New("cascade must be block or soft_delete")
+	}
+}
+
+func (u User) ToResponse() *UserResponse {
+	return &UserResponse{
+		ID:        u.ID,
+		Name:      u.Name,
+		Email:     u.Email,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
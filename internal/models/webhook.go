@@ -0,0 +1,187 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const (
+	WebhookEventRenewing = "subscription.renewing"
+	WebhookEventEnding   = "subscription.ending"
+)
+
+var WebhookEvents = []string{WebhookEventRenewing, WebhookEventEnding}
+
+type Webhook struct {
+	ID        int
+	URL       string
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+}
+
+// @Description Request to register or update webhook
+type WebhookRequest struct {
+	// @Description URL notifications are posted to
+	// @Example https://example.com/hooks/subscriptions
+	URL string `json:"url"`
+
+	// @Description Secret used to sign payloads with HMAC-SHA256, generated on creation and kept on update if empty
+	// @Example s3cr3t
+	Secret string `json:"secret"`
+
+	// @Description Events to notify about: subscription.renewing, subscription.ending. All events if empty
+	// @Example ["subscription.renewing"]
+	Events []string `json:"events"`
+}
+
+// @Description Response with information about webhook
+type WebhookResponse struct {
+	// @Description Integer ID of webhook
+	// @Example 1
+	ID int `json:"id"`
+
+	// @Description URL notifications are posted to
+	// @Example https://example.com/hooks/subscriptions
+	URL string `json:"url"`
+
+	// @Description Secret used to sign payloads, returned only when webhook is created
+	// @Example s3cr3t
+	Secret string `json:"secret,omitempty"`
+
+	// @Description Events to notify about, all events if empty
+	// @Example ["subscription.renewing"]
+	Events []string `json:"events"`
+
+	// @Description Time of registration in RFC 3339 format
+	// @Example 2025-07-01T12:00:00Z
+	CreatedAt string `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	WebhookID      int
+	URL            string
+	Secret         string
+	Event          string
+	SubscriptionID int
+	OccursOn       time.Time
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      *string
+	LastStatusCode *int
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+}
+
+// @Description Delivery log entry of webhook notification
+type WebhookDeliveryResponse struct {
+	// @Description Integer ID of delivery
+	// @Example 1
+	ID int64 `json:"id"`
+
+	// @Description Notified event
+	// @Example subscription.renewing
+	Event string `json:"event"`
+
+	// @Description Integer ID of subscription record the event is about
+	// @Example 1
+	SubscriptionID int `json:"subscription_id"`
+
+	// @Description Date the renewal or end happens on, format: YYYY-MM-DD
+	// @Example 2025-08-01
+	OccursOn string `json:"occurs_on"`
+
+	// @Description Delivery status: pending, delivered or failed
+	// @Example delivered
+	Status string `json:"status"`
+
+	// @Description Number of delivery attempts made
+	// @Example 1
+	Attempts int `json:"attempts"`
+
+	// @Description HTTP status code of the last attempt
+	// @Example 200
+	LastStatusCode *int `json:"last_status_code,omitempty"`
+
+	// @Description Error of the last failed attempt
+	// @Example unexpected status code 500
+	LastError *string `json:"last_error,omitempty"`
+
+	// @Description Time of successful delivery in RFC 3339 format
+	// @Example 2025-07-29T12:00:00Z
+	DeliveredAt *string `json:"delivered_at,omitempty"`
+
+	// @Description Sent payload
+	Payload json.RawMessage `json:"payload" swaggertype:"object"`
+}
+
+func (req WebhookRequest) ToWebhook() (*Webhook, error) {
+	parsed, err := url.Parse(req.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errors.New("Invalid url, must be absolute http or https URL")
+	}
+
+	events := req.Events
+	if events == nil {
+		events = []string{}
+	}
+	for _, event := range events {
+		if !slices.Contains(WebhookEvents, event) {
+			return nil, fmt.Errorf("Unknown event %s, must be one of %v", event, WebhookEvents)
+		}
+	}
+
+	return &Webhook{
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: events,
+	}, nil
+}
+
+func (hook Webhook) ToResponse() *WebhookResponse {
+	return &WebhookResponse{
+		ID:        hook.ID,
+		URL:       hook.URL,
+		Events:    hook.Events,
+		CreatedAt: hook.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (d WebhookDelivery) ToResponse() *WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:             d.ID,
+		Event:          d.Event,
+		SubscriptionID: d.SubscriptionID,
+		OccursOn:       d.OccursOn.Format(time.DateOnly),
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		Payload:        d.Payload,
+	}
+
+	if d.DeliveredAt != nil {
+		temp := d.DeliveredAt.UTC().Format(time.RFC3339)
+		resp.DeliveredAt = &temp
+	}
+
+	return &resp
+}
+
+func GenerateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("Failed to generate webhook secret: %v", err)
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,167 @@
+package notifier
+
+import (
+	"Effective-Mobile-Test/internal/config"
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	claimLimit = 100
+
+	// maxRetryBackoff caps delay between attempts, so doubling backoff of many attempts can't overflow
+	maxRetryBackoff = 24 * time.Hour
+)
+
+// Notifier periodically finds subscriptions renewing or ending soon and delivers notifications to registered webhooks
+type Notifier struct {
+	repo   repository.WebhookRepositoryInterface
+	client *http.Client
+	cfg    *config.Config
+	log    *slog.Logger
+}
+
+func New(repo repository.WebhookRepositoryInterface, cfg *config.Config, log *slog.Logger) *Notifier {
+	return &Notifier{
+		repo:   repo,
+		client: &http.Client{Timeout: 10 * time.Second},
+		cfg:    cfg,
+		log:    log,
+	}
+}
+
+// Run executes notification cycles every configured interval until ctx is cancelled
+func (n *Notifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.cfg.NotifyInterval)
+	defer ticker.Stop()
+
+	for {
+		n.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n *Notifier) RunOnce(ctx context.Context) {
+	now := time.Now().UTC()
+
+	enqueued, err := n.repo.EnqueueNotifications(ctx, now, now.Add(n.cfg.NotifyWindow))
+	if err != nil {
+		n.log.Error("Failed to enqueue webhook notifications", "error", err)
+	} else if enqueued > 0 {
+		n.log.Info("Webhook notifications enqueued", "amount", enqueued)
+	}
+
+	for {
+		deliveries, err := n.repo.ClaimDueDeliveries(ctx, claimLimit, 2*n.client.Timeout)
+		if err != nil {
+			n.log.Error("Failed to claim webhook deliveries", "error", err)
+			return
+		}
+
+		for _, delivery := range deliveries {
+			n.deliver(ctx, delivery)
+		}
+
+		if len(deliveries) < claimLimit || ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (n *Notifier) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	statusCode, err := n.send(ctx, delivery)
+	if err == nil {
+		if err := n.repo.MarkDelivered(ctx, delivery.ID, statusCode); err != nil {
+			n.log.Error("Failed to mark webhook delivery as delivered", "id", delivery.ID, "error", err)
+		}
+		return
+	}
+
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+
+	attempt := delivery.Attempts + 1
+	var nextAttemptAt *time.Time
+	if attempt < n.cfg.NotifyMaxAttempts {
+		next := time.Now().Add(n.retryBackoff(attempt))
+		nextAttemptAt = &next
+	}
+
+	n.log.Warn("Webhook delivery failed", "id", delivery.ID, "attempt", attempt, "retry", nextAttemptAt != nil, "error", err)
+
+	if err := n.repo.MarkFailed(ctx, delivery.ID, code, err.Error(), nextAttemptAt); err != nil {
+		n.log.Error("Failed to record failed webhook delivery", "id", delivery.ID, "error", err)
+	}
+}
+
+// retryBackoff returns delay before retry of failed attempt: configured backoff doubled after every
+// attempt but the first, up to maxRetryBackoff
+func (n *Notifier) retryBackoff(attempt int) time.Duration {
+	backoff := n.cfg.NotifyRetryBackoff
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxRetryBackoff)
+}
+
+func (n *Notifier) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns hex encoded HMAC-SHA256 of "timestamp.payload" with webhook secret as key,
+// receivers recompute it to verify that payload came from this service and was not replayed
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,361 @@
+package notifier
+
+import (
+	"Effective-Mobile-Test/internal/config"
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeWebhookRepo keeps webhooks and deliveries in memory, deduplicating enqueued notifications
+// by webhook, event, subscription and date like UNIQUE constraint of webhook_delivery does
+type fakeWebhookRepo struct {
+	mu         sync.Mutex
+	webhooks   []*models.Webhook
+	upcoming   []*models.WebhookDelivery
+	deliveries []*models.WebhookDelivery
+}
+
+func (r *fakeWebhookRepo) Create(ctx context.Context, webhook *models.Webhook) error {
+	return nil
+}
+
+func (r *fakeWebhookRepo) GetByID(ctx context.Context, id int) (*models.Webhook, error) {
+	return nil, nil
+}
+
+func (r *fakeWebhookRepo) Update(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	return webhook, nil
+}
+
+func (r *fakeWebhookRepo) DeleteByID(ctx context.Context, id int) error {
+	return nil
+}
+
+func (r *fakeWebhookRepo) List(ctx context.Context) ([]*models.Webhook, error) {
+	return r.webhooks, nil
+}
+
+func (r *fakeWebhookRepo) ListDeliveries(ctx context.Context, webhookID int) ([]*models.WebhookDelivery, error) {
+	return r.deliveries, nil
+}
+
+func (r *fakeWebhookRepo) EnqueueNotifications(ctx context.Context, from, to time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var enqueued int64
+	for _, webhook := range r.webhooks {
+		for _, event := range r.upcoming {
+			if r.find(webhook.ID, event) != nil {
+				continue
+			}
+
+			delivery := *event
+			delivery.ID = int64(len(r.deliveries) + 1)
+			delivery.WebhookID = webhook.ID
+			delivery.Status = "pending"
+			delivery.NextAttemptAt = time.Now()
+			r.deliveries = append(r.deliveries, &delivery)
+			enqueued++
+		}
+	}
+
+	return enqueued, nil
+}
+
+func (r *fakeWebhookRepo) find(webhookID int, event *models.WebhookDelivery) *models.WebhookDelivery {
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookID == webhookID && delivery.Event == event.Event &&
+			delivery.SubscriptionID == event.SubscriptionID && delivery.OccursOn.Equal(event.OccursOn) {
+			return delivery
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeWebhookRepo) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var claimed []*models.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		if delivery.Status != "pending" || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+
+		delivery.NextAttemptAt = now.Add(lease)
+		for _, webhook := range r.webhooks {
+			if webhook.ID == delivery.WebhookID {
+				delivery.URL = webhook.URL
+				delivery.Secret = webhook.Secret
+			}
+		}
+
+		claim := *delivery
+		claimed = append(claimed, &claim)
+	}
+
+	return claimed, nil
+}
+
+func (r *fakeWebhookRepo) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery := r.deliveries[id-1]
+	delivery.Status = "delivered"
+	delivery.Attempts++
+	delivery.LastStatusCode = &statusCode
+
+	return nil
+}
+
+func (r *fakeWebhookRepo) MarkFailed(ctx context.Context, id int64, statusCode *int, reason string, nextAttemptAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery := r.deliveries[id-1]
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = &reason
+	if nextAttemptAt == nil {
+		delivery.Status = "failed"
+	} else {
+		delivery.NextAttemptAt = *nextAttemptAt
+	}
+
+	return nil
+}
+
+// expireDelays makes every pending delivery due, as if its retry backoff or lease has passed
+func (r *fakeWebhookRepo) expireDelays() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, delivery := range r.deliveries {
+		delivery.NextAttemptAt = time.Now().Add(-time.Second)
+	}
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newStub starts webhook receiver answering with status and recording requests it got
+func newStub(t *testing.T, status int) (*httptest.Server, func() []receivedRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var received []receivedRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		received = append(received, receivedRequest{header: r.Header.Clone(), body: body})
+		mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []receivedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedRequest(nil), received...)
+	}
+}
+
+func newTestNotifier(url string, maxAttempts int, backoff time.Duration) (*Notifier, *fakeWebhookRepo) {
+	payload, _ := json.Marshal(map[string]any{"event": "subscription.renewing", "subscription": map[string]any{"id": 1}})
+
+	repo := &fakeWebhookRepo{
+		webhooks: []*models.Webhook{{ID: 1, URL: url, Secret: "secret"}},
+		upcoming: []*models.WebhookDelivery{{
+			Event:          "subscription.renewing",
+			SubscriptionID: 1,
+			OccursOn:       time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC),
+			Payload:        payload,
+		}},
+	}
+
+	cfg := &config.Config{
+		NotifyWindow:       72 * time.Hour,
+		NotifyMaxAttempts:  maxAttempts,
+		NotifyRetryBackoff: backoff,
+	}
+
+	return New(repo, cfg, slog.New(slog.NewTextHandler(io.Discard, nil))), repo
+}
+
+func TestDeliverySignatureVerifies(t *testing.T) {
+	server, received := newStub(t, http.StatusOK)
+	notifier, repo := newTestNotifier(server.URL, 3, time.Minute)
+
+	notifier.RunOnce(context.Background())
+
+	requests := received()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+
+	request := requests[0]
+	timestamp := request.header.Get(TimestampHeader)
+	if timestamp == "" {
+		t.Fatalf("%s header is missing", TimestampHeader)
+	}
+
+	want := "sha256=" + Sign("secret", timestamp, request.body)
+	if got := request.header.Get(SignatureHeader); got != want {
+		t.Errorf("signature is %q, want %q", got, want)
+	}
+
+	if got := Sign("other", timestamp, request.body); "sha256="+got == request.header.Get(SignatureHeader) {
+		t.Errorf("signature verifies with wrong secret")
+	}
+
+	if event := request.header.Get(EventHeader); event != "subscription.renewing" {
+		t.Errorf("%s is %q, want subscription.renewing", EventHeader, event)
+	}
+
+	if status := repo.deliveries[0].Status; status != "delivered" {
+		t.Errorf("delivery status is %q, want delivered", status)
+	}
+}
+
+func TestServerErrorIsRetriedWithBackoff(t *testing.T) {
+	server, received := newStub(t, http.StatusInternalServerError)
+	backoff := time.Minute
+	notifier, repo := newTestNotifier(server.URL, 5, backoff)
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		before := time.Now()
+		notifier.RunOnce(context.Background())
+		after := time.Now()
+
+		if got := len(received()); got != attempt {
+			t.Fatalf("attempt %d: got %d requests, want %d", attempt, got, attempt)
+		}
+
+		delivery := repo.deliveries[0]
+		if delivery.Status != "pending" {
+			t.Fatalf("attempt %d: delivery status is %q, want pending", attempt, delivery.Status)
+		}
+		if delivery.LastStatusCode == nil || *delivery.LastStatusCode != http.StatusInternalServerError {
+			t.Errorf("attempt %d: last status code is %v, want 500", attempt, delivery.LastStatusCode)
+		}
+
+		delay := backoff << (attempt - 1)
+		if delivery.NextAttemptAt.Before(before.Add(delay)) || delivery.NextAttemptAt.After(after.Add(delay)) {
+			t.Errorf("attempt %d: next attempt in %v, want %v", attempt, delivery.NextAttemptAt.Sub(before), delay)
+		}
+
+		// Retry is not due before its backoff
+		notifier.RunOnce(context.Background())
+		if got := len(received()); got != attempt {
+			t.Fatalf("attempt %d: retried before backoff, got %d requests", attempt, got)
+		}
+
+		repo.expireDelays()
+	}
+}
+
+func TestDeliveryStopsAfterMaxAttempts(t *testing.T) {
+	server, received := newStub(t, http.StatusBadGateway)
+	notifier, repo := newTestNotifier(server.URL, 3, time.Minute)
+
+	for i := 0; i < 5; i++ {
+		notifier.RunOnce(context.Background())
+		repo.expireDelays()
+	}
+
+	if got := len(received()); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+
+	delivery := repo.deliveries[0]
+	if delivery.Status != "failed" {
+		t.Errorf("delivery status is %q, want failed", delivery.Status)
+	}
+	if delivery.Attempts != 3 {
+		t.Errorf("delivery made %d attempts, want 3", delivery.Attempts)
+	}
+}
+
+func TestSecondScanOfRenewalSendsNothing(t *testing.T) {
+	server, received := newStub(t, http.StatusOK)
+	notifier, repo := newTestNotifier(server.URL, 3, time.Minute)
+
+	notifier.RunOnce(context.Background())
+	repo.expireDelays()
+	notifier.RunOnce(context.Background())
+
+	if got := len(received()); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+	if got := len(repo.deliveries); got != 1 {
+		t.Errorf("got %d deliveries, want 1", got)
+	}
+}
+
+func TestUnfinishedDeliveryIsReclaimedAfterLease(t *testing.T) {
+	server, received := newStub(t, http.StatusOK)
+	notifier, repo := newTestNotifier(server.URL, 3, time.Minute)
+
+	repo.EnqueueNotifications(context.Background(), time.Now(), time.Now())
+
+	// Instance claimed delivery and stopped before marking it
+	claimed, _ := repo.ClaimDueDeliveries(context.Background(), claimLimit, time.Minute)
+	if len(claimed) != 1 {
+		t.Fatalf("claimed %d deliveries, want 1", len(claimed))
+	}
+
+	notifier.RunOnce(context.Background())
+	if got := len(received()); got != 0 {
+		t.Fatalf("delivery sent during lease of another instance, got %d requests", got)
+	}
+
+	repo.expireDelays()
+	notifier.RunOnce(context.Background())
+	if got := len(received()); got != 1 {
+		t.Errorf("got %d requests after lease expired, want 1", got)
+	}
+}
+
+func TestRetryBackoffIsCapped(t *testing.T) {
+	notifier, _ := newTestNotifier("", 100, 30*time.Second)
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{5, 8 * time.Minute},
+		{40, maxRetryBackoff},
+		{100, maxRetryBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.attempt), func(t *testing.T) {
+			if got := notifier.retryBackoff(tt.attempt); got != tt.want {
+				t.Errorf("retryBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/repository"
+	"context"
+	"log/slog"
+	"time"
+)
+
+const batchSize = 100
+
+// Relay publishes events from outbox table to sink in sequence order
+type Relay struct {
+	repo     repository.OutboxRepositoryInterface
+	sink     Sink
+	interval time.Duration
+	log      *slog.Logger
+}
+
+func NewRelay(repo repository.OutboxRepositoryInterface, sink Sink, interval time.Duration, log *slog.Logger) *Relay {
+	return &Relay{
+		repo:     repo,
+		sink:     sink,
+		interval: interval,
+		log:      log,
+	}
+}
+
+// Run publishes pending events every interval until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Relay) RunOnce(ctx context.Context) {
+	for {
+		published, err := r.repo.PublishPending(ctx, batchSize, func(event *models.Event) error {
+			err := r.sink.Publish(ctx, event)
+			if err != nil {
+				r.log.Warn("Failed to publish event, will retry", "sequence", event.Sequence, "error", err)
+			}
+			return err
+		})
+		if err != nil {
+			r.log.Error("Failed to relay outbox events", "error", err)
+			return
+		}
+
+		if published > 0 {
+			r.log.Debug("Outbox events published", "amount", published)
+		}
+
+		if published < batchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package outbox
+
+import (
+	"Effective-Mobile-Test/internal/config"
+	"Effective-Mobile-Test/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const SequenceHeader = "X-Event-Sequence"
+
+// Sink receives published events. Publish must return error unless event was durably accepted,
+// because failed event is retried before any later one
+type Sink interface {
+	Publish(ctx context.Context, event *models.Event) error
+}
+
+// WriterSink writes events as NDJSON lines, used for stdout and files
+type WriterSink struct {
+	mu   sync.Mutex
+	w    io.Writer
+	sync func() error
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Publish(ctx context.Context, event *models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	if s.sync != nil {
+		return s.sync()
+	}
+
+	return nil
+}
+
+// NewFileSink appends events to file at path, creating it if needed. Every event is synced to disk
+// before it is acknowledged, so event marked published is not lost on crash
+func NewFileSink(path string) (*WriterSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open outbox file: %v", err)
+	}
+
+	return &WriterSink{w: file, sync: file.Sync}, nil
+}
+
+// WebhookSink posts every event as JSON to URL, sequence number is also sent in X-Event-Sequence header
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, event *models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SequenceHeader, strconv.FormatInt(event.Sequence, 10))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Producer is implemented by message broker clients, e.g. Kafka or NATS, to plug them in as sink
+type Producer interface {
+	Produce(ctx context.Context, topic, key string, value []byte) error
+}
+
+// BrokerSink publishes events to topic of message broker keyed by subscription ID,
+// so events of one subscription stay ordered within a partition
+type BrokerSink struct {
+	producer Producer
+	topic    string
+}
+
+func NewBrokerSink(producer Producer, topic string) *BrokerSink {
+	return &BrokerSink{producer: producer, topic: topic}
+}
+
+func (s *BrokerSink) Publish(ctx context.Context, event *models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return s.producer.Produce(ctx, s.topic, strconv.Itoa(event.SubscriptionID), data)
+}
+
+// NewSinkFromConfig creates sink selected by OUTBOX_SINK, nil means events are kept in outbox unpublished
+func NewSinkFromConfig(cfg *config.Config) (Sink, error) {
+	switch cfg.OutboxSink {
+	case "none", "":
+		return nil, nil
+	case "stdout":
+		return NewWriterSink(os.Stdout), nil
+	case "file":
+		return NewFileSink(cfg.OutboxFile)
+	case "webhook":
+		if cfg.OutboxWebhookURL == "" {
+			return nil, fmt.Errorf("OUTBOX_WEBHOOK_URL must be set for webhook outbox sink")
+		}
+		return NewWebhookSink(cfg.OutboxWebhookURL), nil
+	default:
+		return nil, fmt.Errorf("Unknown outbox sink %s, must be one of none, stdout, file, webhook", cfg.OutboxSink)
+	}
+}
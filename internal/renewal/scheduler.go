@@ -0,0 +1,73 @@
+package renewal
+
+import (
+	"Effective-Mobile-Test/internal/repository"
+	"Effective-Mobile-Test/internal/requestmeta"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// Actor is recorded in audit log as author of changes made by the job
+const Actor = "renewal-job"
+
+// Scheduler periodically ends subscriptions whose end date has passed and renews auto-renewing ones
+type Scheduler struct {
+	repo     repository.RenewalRepositoryInterface
+	interval time.Duration
+	log      *slog.Logger
+}
+
+func NewScheduler(repo repository.RenewalRepositoryInterface, interval time.Duration, log *slog.Logger) *Scheduler {
+	return &Scheduler{
+		repo:     repo,
+		interval: interval,
+		log:      log,
+	}
+}
+
+// Run executes renewal cycles every interval until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ctx = requestmeta.WithActor(ctx, Actor)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	startedAt := time.Now().UTC()
+
+	run, err := s.repo.Run(ctx, startedAt)
+	if errors.Is(err, repository.ErrRenewalRunning) {
+		s.log.Debug("Renewal cycle skipped, another instance is running it")
+		return
+	}
+
+	if err != nil {
+		s.log.Error("Renewal cycle failed", "error", err)
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.repo.RecordFailure(ctx, startedAt, err); err != nil {
+			s.log.Error("Failed to record renewal run", "error", err)
+		}
+		return
+	}
+
+	if len(run.ConflictingIDs) > 0 {
+		s.log.Warn("Subscription records not renewed as they would overlap other records", "ids", run.ConflictingIDs)
+	}
+
+	s.log.Info("Renewal cycle finished", "id", run.ID, "ended", run.Ended, "renewed", run.Renewed, "conflicting", len(run.ConflictingIDs))
+}
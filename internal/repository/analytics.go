@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type AnalyticsRepositoryInterface interface {
+	TopServices(ctx context.Context, period *models.AnalyticsPeriod, by string, limit int) ([]*models.ServiceSpend, error)
+	SpendGrowth(ctx context.Context, period *models.AnalyticsPeriod) ([]*models.MonthlySpend, error)
+	Churn(ctx context.Context, period *models.AnalyticsPeriod) ([]*models.MonthlyChurn, error)
+	Lifetime(ctx context.Context, serviceName string) ([]*models.ServiceLifetime, error)
+}
+
+type AnalyticsRepo struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepo(db *sql.DB) AnalyticsRepositoryInterface {
+	return &AnalyticsRepo{db: db}
+}
+
+// monthSeries lists first days of months from $1 to $2 inclusive
+const monthSeries = `
+	month AS (
+		SELECT
+			generate_series(
+				date_trunc('month', $1::date),
+				date_trunc('month', $2::date),
+				interval '1 month'
+			)::date AS start
+	)
+`
+
+// activeIn matches subscription records s charged in month m
+const activeIn = `s.start_date < m.start + interval '1 month' AND (s.end_date IS NULL OR s.end_date >= m.start)`
+
+// chargesIn counts billing days of subscription record s within month m, zero if it ends
+// or starts in the month on the other side of its billing day
+const chargesIn = `subscription_charge_count(s.start_date, s.end_date, s.billing_day, m.start, (m.start + interval '1 month' - interval '1 day')::date)`
+
+// TopServices ranks services by sum of monthly charges over the period or by number of distinct users
+// subscribed during it
+func (r *AnalyticsRepo) TopServices(ctx context.Context, period *models.AnalyticsPeriod, by string, limit int) ([]*models.ServiceSpend, error) {
+	orderBy := "spend DESC, subscribers DESC"
+	if by == models.TopServicesBySubscribers {
+		orderBy = "subscribers DESC, spend DESC"
+	}
+
+	query := `
+		WITH ` + monthSeries + `
+		SELECT
+			s.service_name,
+			SUM(s.price * ` + chargesIn + `) AS spend,
+			COUNT(DISTINCT s.user_id) AS subscribers
+		FROM
+			month m
+			JOIN subscription_record s ON ` + activeIn + `
+		GROUP BY
+			s.service_name
+		ORDER BY
+			` + orderBy + `,
+			s.service_name
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, period.From, period.To, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var services []*models.ServiceSpend
+	for rows.Next() {
+		var service models.ServiceSpend
+		if err := rows.Scan(&service.ServiceName, &service.Spend, &service.Subscribers); err != nil {
+			return nil, fmt.Errorf("Failed to scan service spend: %v", err)
+		}
+
+		services = append(services, &service)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while ranking services: %v", err)
+	}
+
+	return services, nil
+}
+
+// SpendGrowth compares spend of every month of the period with the previous month, so the series
+// is computed starting one month before the period
+func (r *AnalyticsRepo) SpendGrowth(ctx context.Context, period *models.AnalyticsPeriod) ([]*models.MonthlySpend, error) {
+	query := `
+		WITH ` + monthSeries + `,
+		spend AS (
+			SELECT
+				m.start,
+				COALESCE(SUM(s.price * ` + chargesIn + `), 0) AS total
+			FROM
+				month m
+				LEFT JOIN subscription_record s ON ` + activeIn + `
+			GROUP BY
+				m.start
+		),
+		growth AS (
+			SELECT
+				start,
+				total,
+				total - LAG(total) OVER w AS change,
+				ROUND(100.0 * (total - LAG(total) OVER w) / NULLIF(LAG(total) OVER w, 0), 2)::float8 AS growth
+			FROM
+				spend
+			WINDOW w AS (ORDER BY start)
+		)
+		SELECT
+			start,
+			total,
+			change,
+			growth
+		FROM
+			growth
+		WHERE
+			start >= $3
+		ORDER BY
+			start
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, period.From.AddDate(0, -1, 0), period.To, period.From)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var months []*models.MonthlySpend
+	for rows.Next() {
+		var month models.MonthlySpend
+		var growth sql.NullFloat64
+
+		if err := rows.Scan(&month.Month, &month.Spend, &month.Change, &growth); err != nil {
+			return nil, fmt.Errorf("Failed to scan monthly spend: %v", err)
+		}
+
+		if growth.Valid {
+			month.Growth = &growth.Float64
+		}
+
+		months = append(months, &month)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while computing spend growth: %v", err)
+	}
+
+	return months, nil
+}
+
+// Churn counts subscriptions started and ended in every month of the period. Subscription is
+// a continuous presence of user and service pair, so records split or renewed without a gap
+// neither start nor end one
+func (r *AnalyticsRepo) Churn(ctx context.Context, period *models.AnalyticsPeriod) ([]*models.MonthlyChurn, error) {
+	query := `
+		WITH ` + monthSeries + `,
+		churn AS (
+			SELECT
+				m.start,
+				(
+					SELECT
+						COUNT(DISTINCT (s.user_id, s.service_name))
+					FROM
+						subscription_record s
+					WHERE
+						` + activeIn + `
+				) AS active,
+				(
+					SELECT
+						COUNT(DISTINCT (s.user_id, s.service_name))
+					FROM
+						subscription_record s
+					WHERE
+						date_trunc('month', s.start_date) = m.start
+						AND NOT EXISTS (
+							SELECT 1
+							FROM subscription_record p
+							WHERE p.user_id = s.user_id
+								AND p.service_name = s.service_name
+								AND p.start_date < m.start
+								AND (p.end_date IS NULL OR p.end_date >= m.start - interval '1 month')
+						)
+				) AS started,
+				(
+					SELECT
+						COUNT(DISTINCT (s.user_id, s.service_name))
+					FROM
+						subscription_record s
+					WHERE
+						date_trunc('month', s.end_date) = m.start
+						AND NOT EXISTS (
+							SELECT 1
+							FROM subscription_record n
+							WHERE n.user_id = s.user_id
+								AND n.service_name = s.service_name
+								AND n.start_date < m.start + interval '2 month'
+								AND (n.end_date IS NULL OR n.end_date >= m.start + interval '1 month')
+						)
+				) AS ended
+			FROM
+				month m
+		)
+		SELECT
+			start,
+			active,
+			started,
+			ended,
+			ROUND(100.0 * ended / NULLIF(active, 0), 2)::float8
+		FROM
+			churn
+		ORDER BY
+			start
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var months []*models.MonthlyChurn
+	for rows.Next() {
+		var month models.MonthlyChurn
+		var churnRate sql.NullFloat64
+
+		if err := rows.Scan(&month.Month, &month.Active, &month.Started, &month.Ended, &churnRate); err != nil {
+			return nil, fmt.Errorf("Failed to scan monthly churn: %v", err)
+		}
+
+		if churnRate.Valid {
+			month.ChurnRate = &churnRate.Float64
+		}
+
+		months = append(months, &month)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while computing churn: %v", err)
+	}
+
+	return months, nil
+}
+
+// Lifetime averages number of months subscriptions to every service last. Overlapping or consecutive
+// records of the same user are joined into one subscription, active ones last until the current date
+func (r *AnalyticsRepo) Lifetime(ctx context.Context, serviceName string) ([]*models.ServiceLifetime, error) {
+	query := `
+		WITH record AS (
+			SELECT
+				user_id,
+				service_name,
+				start_date,
+				COALESCE(end_date, GREATEST(current_date, start_date)) AS end_date
+			FROM
+				subscription_record
+			WHERE
+				$1 = '' OR service_name = $1
+		),
+		flagged AS (
+			SELECT
+				*,
+				CASE
+					WHEN start_date > MAX(end_date) OVER (
+						PARTITION BY user_id, service_name
+						ORDER BY start_date, end_date
+						ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING
+					) + interval '1 day' THEN 1
+					ELSE 0
+				END AS gap
+			FROM
+				record
+		),
+		numbered AS (
+			SELECT
+				*,
+				SUM(gap) OVER (
+					PARTITION BY user_id, service_name
+					ORDER BY start_date, end_date
+					ROWS UNBOUNDED PRECEDING
+				) AS period
+			FROM
+				flagged
+		),
+		subscription AS (
+			SELECT
+				service_name,
+				MIN(start_date) AS start_date,
+				MAX(end_date) AS end_date
+			FROM
+				numbered
+			GROUP BY
+				service_name,
+				user_id,
+				period
+		)
+		SELECT
+			service_name,
+			COUNT(*),
+			ROUND(AVG(
+				(EXTRACT(YEAR FROM age(end_date, start_date)) * 12 + EXTRACT(MONTH FROM age(end_date, start_date)) + 1)::numeric
+			), 1)::float8
+		FROM
+			subscription
+		GROUP BY
+			service_name
+		ORDER BY
+			service_name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var services []*models.ServiceLifetime
+	for rows.Next() {
+		var service models.ServiceLifetime
+		if err := rows.Scan(&service.ServiceName, &service.Subscriptions, &service.AverageMonths); err != nil {
+			return nil, fmt.Errorf("Failed to scan service lifetime: %v", err)
+		}
+
+		services = append(services, &service)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while computing lifetime: %v", err)
+	}
+
+	return services, nil
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/requestmeta"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type AuditRepositoryInterface interface {
+	History(ctx context.Context, subscriptionID int) ([]*models.AuditEntry, error)
+	Query(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, error)
+}
+
+type AuditRepo struct {
+	db *sql.DB
+}
+
+func NewAuditRepo(db *sql.DB) AuditRepositoryInterface {
+	return &AuditRepo{db: db}
+}
+
+func (r *AuditRepo) History(ctx context.Context, subscriptionID int) ([]*models.AuditEntry, error) {
+	return r.Query(ctx, &models.AuditFilter{SubscriptionID: &subscriptionID})
+}
+
+func (r *AuditRepo) Query(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, error) {
+	query := `
+		SELECT
+			id,
+			subscription_id,
+			operation,
+			actor,
+			request_id,
+			changed_at,
+			before,
+			after
+		FROM
+			subscription_audit
+		WHERE
+			($1::int IS NULL OR subscription_id = $1)
+			AND ($2 = '' OR actor = $2)
+			AND ($3::timestamptz IS NULL OR changed_at >= $3)
+			AND ($4::timestamptz IS NULL OR changed_at <= $4)
+		ORDER BY
+			changed_at,
+			id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.SubscriptionID, filter.Actor, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		var entry models.AuditEntry
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.SubscriptionID,
+			&entry.Operation,
+			&entry.Actor,
+			&entry.RequestID,
+			&entry.ChangedAt,
+			&entry.Before,
+			&entry.After,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan audit entry: %v", err)
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while querying audit log: %v", err)
+	}
+
+	return entries, nil
+}
+
+// snapshotSubscription returns JSON representation of subscription record as it is stored in table
+// with its members, locking the row until the end of transaction
+func snapshotSubscription(ctx context.Context, tx *sql.Tx, id int) ([]byte, error) {
+	query := `
+		SELECT
+			to_jsonb(s) || jsonb_build_object(
+				'members',
+				COALESCE(
+					(
+						SELECT
+							jsonb_agg(jsonb_build_object('user_id', m.user_id, 'weight', m.weight) ORDER BY m.user_id)
+						FROM
+							subscription_member m
+						WHERE
+							m.subscription_id = s.id
+					),
+					'[]'::jsonb
+				)
+			)
+		FROM
+			subscription_record s
+		WHERE
+			id = $1
+		FOR UPDATE OF s
+	`
+
+	var snapshot []byte
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func writeAudit(ctx context.Context, tx *sql.Tx, operation string, subscriptionID int, before, after []byte) error {
+	query := `
+		INSERT INTO
+			subscription_audit (
+				subscription_id,
+				operation,
+				actor,
+				request_id,
+				before,
+				after
+			)
+		VALUES
+			($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := tx.ExecContext(
+		ctx,
+		query,
+		subscriptionID,
+		operation,
+		requestmeta.Actor(ctx),
+		requestmeta.RequestID(ctx),
+		jsonOrNil(before),
+		jsonOrNil(after),
+	)
+	if err != nil {
+		return fmt.Errorf("Failed to write audit entry: %v", err)
+	}
+
+	return nil
+}
+
+func jsonOrNil(data []byte) any {
+	if data == nil {
+		return nil
+	}
+
+	return string(data)
+}
+
+// subscriptionSnapshotQuery rebuilds subscription_record as it stood at given moment
+// from the latest audit entry of every record made before that moment. Snapshots taken before records
+// had billing day are of month precision: they end at the last day of end month and are billed on start day
+const subscriptionSnapshotQuery = `
+	SELECT
+		record.*
+	FROM
+		(
+			SELECT DISTINCT ON (subscription_id)
+				operation,
+				after
+			FROM
+				subscription_audit
+			WHERE
+				changed_at <= %s
+			ORDER BY
+				subscription_id,
+				changed_at DESC,
+				id DESC
+		) latest
+		CROSS JOIN LATERAL jsonb_populate_record(
+			NULL::subscription_record,
+			CASE
+				WHEN latest.after ? 'billing_day' THEN latest.after
+				ELSE latest.after || jsonb_build_object(
+					'end_date', (date_trunc('month', (latest.after->>'end_date')::date) + interval '1 month' - interval '1 day')::date,
+					'billing_day', EXTRACT(DAY FROM (latest.after->>'start_date')::date)::int
+				)
+			END
+		) record
+	WHERE
+		latest.operation <> 'delete'
+`
+
+// subscriptionSource returns FROM clause item that reads subscription_record either as it is now
+// or, when asOf is set, as it stood at that moment; placeholder is the query parameter holding asOf
+func subscriptionSource(asOf *time.Time, placeholder string) string {
+	if asOf == nil {
+		return "subscription_record"
+	}
+
+	return "(" + fmt.Sprintf(subscriptionSnapshotQuery, placeholder) + ") AS subscription_record"
+}
@@ -0,0 +1,412 @@
+package repository
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/requestmeta"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type BudgetRepositoryInterface interface {
+	Create(ctx context.Context, budget *models.Budget) error
+	GetByID(ctx context.Context, id int) (*models.Budget, error)
+	Update(ctx context.Context, budget *models.Budget) (*models.Budget, error)
+	DeleteByID(ctx context.Context, id int) error
+	List(ctx context.Context) ([]*models.Budget, error)
+	Status(ctx context.Context, month time.Time) ([]*models.BudgetStatus, error)
+	ListAlerts(ctx context.Context, filter *models.BudgetAlertFilter) ([]*models.BudgetAlert, error)
+}
+
+type BudgetRepo struct {
+	db *sql.DB
+}
+
+func NewBudgetRepo(db *sql.DB) BudgetRepositoryInterface {
+	return &BudgetRepo{db: db}
+}
+
+// budgetStatusQuery compares budgets with spend of month $1, computed like subscription cost
+// of a single month period, so user budgets count only user's share of shared records
+var budgetStatusQuery = `
+	SELECT
+		b.id,
+		b.user_id,
+		b.service_name,
+		b.monthly_limit,
+		b.created_at,
+		COALESCE(spend.total, 0)
+	FROM
+		budget b
+		CROSS JOIN LATERAL (
+			SELECT
+				ROUND(SUM(s.price * subscription_charge_count(s.start_date, s.end_date, s.billing_day, $1::date, ($1::date + interval '1 month' - interval '1 day')::date)))::int AS total
+			FROM
+				` + chargeSource(nil, "") + ` s
+			WHERE
+				s.start_date < $1::date + interval '1 month'
+				AND (s.end_date IS NULL OR s.end_date >= $1::date)
+				AND (b.user_id IS NULL OR s.user_id = b.user_id)
+				AND (b.service_name IS NULL OR s.service_name = b.service_name)
+		) spend
+`
+
+func (r *BudgetRepo) Create(ctx context.Context, budget *models.Budget) error {
+	query := `
+		INSERT INTO
+			budget (
+				user_id,
+				service_name,
+				monthly_limit
+			)
+		VALUES
+			($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		budget.UserID,
+		budget.ServiceName,
+		budget.MonthlyLimit,
+	).Scan(&budget.ID, &budget.CreatedAt)
+	if isViolation(err, foreignKeyViolation) {
+		return ErrUnknownUser
+	}
+
+	return err
+}
+
+func (r *BudgetRepo) GetByID(ctx context.Context, id int) (*models.Budget, error) {
+	query := `
+		SELECT
+			id,
+			user_id,
+			service_name,
+			monthly_limit,
+			created_at
+		FROM
+			budget
+		WHERE
+			id = $1
+	`
+
+	var budget models.Budget
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&budget.ID,
+		&budget.UserID,
+		&budget.ServiceName,
+		&budget.MonthlyLimit,
+		&budget.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &budget, nil
+}
+
+func (r *BudgetRepo) Update(ctx context.Context, budget *models.Budget) (*models.Budget, error) {
+	query := `
+		UPDATE budget
+		SET
+			user_id = $1,
+			service_name = $2,
+			monthly_limit = $3
+		WHERE id = $4
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		budget.UserID,
+		budget.ServiceName,
+		budget.MonthlyLimit,
+		budget.ID,
+	).Scan(&budget.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("Budget with id %d not found", budget.ID)
+		}
+		if isViolation(err, foreignKeyViolation) {
+			return nil, ErrUnknownUser
+		}
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+func (r *BudgetRepo) DeleteByID(ctx context.Context, id int) error {
+	query := `
+		DELETE FROM budget
+		WHERE id = $1
+	`
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("No budget with id: %d", id)
+	}
+
+	return nil
+}
+
+func (r *BudgetRepo) List(ctx context.Context) ([]*models.Budget, error) {
+	query := `
+		SELECT
+			id,
+			user_id,
+			service_name,
+			monthly_limit,
+			created_at
+		FROM
+			budget
+		ORDER BY
+			id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var budgets []*models.Budget
+	for rows.Next() {
+		var budget models.Budget
+
+		err := rows.Scan(
+			&budget.ID,
+			&budget.UserID,
+			&budget.ServiceName,
+			&budget.MonthlyLimit,
+			&budget.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan budget while listing: %v", err)
+		}
+
+		budgets = append(budgets, &budget)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing budgets: %v", err)
+	}
+
+	return budgets, nil
+}
+
+func (r *BudgetRepo) Status(ctx context.Context, month time.Time) ([]*models.BudgetStatus, error) {
+	return queryBudgetStatus(ctx, r.db, budgetStatusQuery+" ORDER BY b.id", month)
+}
+
+func (r *BudgetRepo) ListAlerts(ctx context.Context, filter *models.BudgetAlertFilter) ([]*models.BudgetAlert, error) {
+	query := `
+		SELECT
+			id,
+			budget_id,
+			subscription_id,
+			month,
+			monthly_limit,
+			spend,
+			actor,
+			request_id,
+			created_at
+		FROM
+			budget_alert
+		WHERE
+			($1::int IS NULL OR budget_id = $1)
+		ORDER BY
+			created_at DESC,
+			id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.BudgetID, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []*models.BudgetAlert
+	for rows.Next() {
+		var alert models.BudgetAlert
+
+		err := rows.Scan(
+			&alert.ID,
+			&alert.BudgetID,
+			&alert.SubscriptionID,
+			&alert.Month,
+			&alert.MonthlyLimit,
+			&alert.Spend,
+			&alert.Actor,
+			&alert.RequestID,
+			&alert.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan budget alert: %v", err)
+		}
+
+		alerts = append(alerts, &alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing budget alerts: %v", err)
+	}
+
+	return alerts, nil
+}
+
+// coveringBudgets compares budgets covering subscription record, its owner or members, against spend
+// of every month affected by it
+func coveringBudgets(ctx context.Context, tx *sql.Tx, subscription *models.Subscription) ([]*models.BudgetStatus, error) {
+	query := budgetStatusQuery + `
+		WHERE
+			(b.user_id IS NULL OR b.user_id = $2 OR b.user_id = ANY($4::uuid[]))
+			AND (b.service_name IS NULL OR b.service_name = $3)
+		ORDER BY
+			b.id
+	`
+
+	members := make([]string, 0, len(subscription.Members))
+	for _, member := range subscription.Members {
+		members = append(members, member.UserID.String())
+	}
+
+	var statuses []*models.BudgetStatus
+	for _, month := range subscription.BudgetCheckMonths() {
+		monthStatuses, err := queryBudgetStatus(ctx, tx, query, month, subscription.UserID, subscription.ServiceName, pq.Array(members))
+		if err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, monthStatuses...)
+	}
+
+	return statuses, nil
+}
+
+// alertBudgets raises alert for every budget and month covering subscription record whose spend is exceeded
+// after write of the record but was not in before, taken by coveringBudgets within the same transaction ahead
+// of the write. Budgets that were already exceeded in the month are not alerted again. Every alert is also
+// published as budget.exceeded event, and alerted budgets are kept in ExceededBudgets
+func alertBudgets(ctx context.Context, tx *sql.Tx, subscription *models.Subscription, before []*models.BudgetStatus) error {
+	after, err := coveringBudgets(ctx, tx, subscription)
+	if err != nil {
+		return err
+	}
+
+	type budgetMonth struct {
+		id    int
+		month time.Time
+	}
+
+	wasExceeded := make(map[budgetMonth]bool, len(before))
+	for _, status := range before {
+		wasExceeded[budgetMonth{status.ID, status.Month}] = status.Exceeded()
+	}
+
+	query := `
+		INSERT INTO
+			budget_alert (
+				budget_id,
+				subscription_id,
+				month,
+				monthly_limit,
+				spend,
+				actor,
+				request_id
+			)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	subscription.ExceededBudgets = nil
+	for _, status := range after {
+		if !status.Exceeded() || wasExceeded[budgetMonth{status.ID, status.Month}] {
+			continue
+		}
+
+		alert := models.BudgetAlert{
+			BudgetID:       status.ID,
+			SubscriptionID: subscription.ID,
+			Month:          status.Month,
+			MonthlyLimit:   status.MonthlyLimit,
+			Spend:          status.Spend,
+			Actor:          requestmeta.Actor(ctx),
+			RequestID:      requestmeta.RequestID(ctx),
+		}
+
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			alert.BudgetID,
+			alert.SubscriptionID,
+			alert.Month,
+			alert.MonthlyLimit,
+			alert.Spend,
+			alert.Actor,
+			alert.RequestID,
+		).Scan(&alert.ID, &alert.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("Failed to raise budget alert: %v", err)
+		}
+
+		if err := writeEvent(ctx, tx, models.EventBudgetExceeded, subscription.ID, alert.ToResponse()); err != nil {
+			return err
+		}
+
+		subscription.ExceededBudgets = append(subscription.ExceededBudgets, status)
+	}
+
+	return nil
+}
+
+func queryBudgetStatus(ctx context.Context, q queryer, query string, month time.Time, args ...any) ([]*models.BudgetStatus, error) {
+	rows, err := q.QueryContext(ctx, query, append([]any{month}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statuses []*models.BudgetStatus
+	for rows.Next() {
+		status := models.BudgetStatus{Month: month}
+
+		err := rows.Scan(
+			&status.ID,
+			&status.UserID,
+			&status.ServiceName,
+			&status.MonthlyLimit,
+			&status.CreatedAt,
+			&status.Spend,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan budget status: %v", err)
+		}
+
+		statuses = append(statuses, &status)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while comparing budgets: %v", err)
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// subscriptionMemberSnapshotQuery rebuilds subscription_member as it stood at given moment from members
+// stored in the latest audit entry of every record made before that moment
+const subscriptionMemberSnapshotQuery = `
+	SELECT
+		latest.subscription_id,
+		(member->>'user_id')::uuid AS user_id,
+		(member->>'weight')::int AS weight
+	FROM
+		(
+			SELECT DISTINCT ON (subscription_id)
+				subscription_id,
+				operation,
+				after
+			FROM
+				subscription_audit
+			WHERE
+				changed_at <= %s
+			ORDER BY
+				subscription_id,
+				changed_at DESC,
+				id DESC
+		) latest
+		CROSS JOIN LATERAL jsonb_array_elements(COALESCE(latest.after->'members', '[]'::jsonb)) member
+	WHERE
+		latest.operation <> 'delete'
+`
+
+// subscriptionMemberSource is subscriptionSource counterpart for members of shared records
+func subscriptionMemberSource(asOf *time.Time, placeholder string) string {
+	if asOf == nil {
+		return "subscription_member"
+	}
+
+	return "(" + fmt.Sprintf(subscriptionMemberSnapshotQuery, placeholder) + ") AS subscription_member"
+}
+
+// chargeSource returns subquery with a row per payer of every subscription record: the owner of record
+// without members or every member of shared one, with price being the part payer is charged. Category
+// of service is always the current one, even for records as they stood at asOf
+func chargeSource(asOf *time.Time, placeholder string) string {
+	return `(
+		SELECT
+			subscription_record.id,
+			subscription_record.service_name,
+			subscription_record.start_date,
+			subscription_record.end_date,
+			subscription_record.tags,
+			subscription_record.cost_center,
+			subscription_record.billing_day,
+			COALESCE(service_category.category, '` + models.UncategorizedCategory + `') AS category,
+			COALESCE(subscription_member.user_id, subscription_record.user_id) AS user_id,
+			subscription_record.price * COALESCE(
+				subscription_member.weight::numeric / SUM(subscription_member.weight) OVER (PARTITION BY subscription_record.id),
+				1
+			) AS price
+		FROM
+			` + subscriptionSource(asOf, placeholder) + `
+			LEFT JOIN ` + subscriptionMemberSource(asOf, placeholder) + ` ON subscription_member.subscription_id = subscription_record.id
+			LEFT JOIN service_category ON service_category.service_name = subscription_record.service_name
+	)`
+}
+
+// writeMembers replaces members of subscription record unless they are not set
+func (r *SubscriptionRepo) writeMembers(ctx context.Context, tx *sql.Tx, subscription *models.Subscription) error {
+	if subscription.Members == nil {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM subscription_member WHERE subscription_id = $1", subscription.ID); err != nil {
+		return fmt.Errorf("Failed to delete subscription members: %v", err)
+	}
+
+	query := `
+		INSERT INTO
+			subscription_member (
+				subscription_id,
+				user_id,
+				weight
+			)
+		VALUES
+			($1, $2, $3)
+	`
+
+	for _, member := range subscription.Members {
+		if r.autoProvisionUsers {
+			if err := provisionUser(ctx, tx, member.UserID); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, query, subscription.ID, member.UserID, member.Weight); err != nil {
+			if isViolation(err, foreignKeyViolation) {
+				return ErrUnknownUser
+			}
+			return fmt.Errorf("Failed to write subscription member: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// loadMembers fills members of subscription records as they stood at asOf or as they are now
+func loadMembers(ctx context.Context, q queryer, subscriptions []*models.Subscription, asOf *time.Time) error {
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Subscription, len(subscriptions))
+	ids := make([]int64, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		byID[subscription.ID] = subscription
+		ids = append(ids, int64(subscription.ID))
+	}
+
+	query := `
+		SELECT
+			subscription_id,
+			user_id,
+			weight
+		FROM
+			` + subscriptionMemberSource(asOf, "$2") + `
+		WHERE
+			subscription_id = ANY($1)
+		ORDER BY
+			subscription_id,
+			user_id
+	`
+
+	args := []any{pq.Array(ids)}
+	if asOf != nil {
+		args = append(args, *asOf)
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var subscriptionID int
+		var member models.SubscriptionMember
+		if err := rows.Scan(&subscriptionID, &member.UserID, &member.Weight); err != nil {
+			return fmt.Errorf("Failed to scan subscription member: %v", err)
+		}
+
+		if subscription, exists := byID[subscriptionID]; exists {
+			subscription.Members = append(subscription.Members, member)
+		}
+	}
+
+	return rows.Err()
+}
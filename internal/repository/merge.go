@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	ErrMergeConflict = errors.New("Subscription records can not be merged")
+	ErrInvalidSplit  = errors.New("Subscription record can not be split")
+)
+
+// Merge combines records of the same user, service, price and billing day whose periods overlap or follow each other
+// into the record with the lowest ID covering their unified period, other records are deleted.
+// Merged record is marked with tags of all of them. Every change is audited and emitted as event.
+// Cost totals stay the same for records following each other, while months covered by several overlapping
+// records are charged once after merge, so totals of those months drop by the overlapping charges
+func (r *SubscriptionRepo) Merge(ctx context.Context, ids []int) (*models.Subscription, error) {
+	var merged *models.Subscription
+
+	// records are locked in order of IDs, so concurrent merges of intersecting sets can't deadlock
+	sortedIDs := append([]int(nil), ids...)
+	sort.Ints(sortedIDs)
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		records := make([]*models.Subscription, 0, len(sortedIDs))
+		for _, id := range sortedIDs {
+			record, err := getSubscriptionForUpdate(ctx, tx, id)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return fmt.Errorf("%w: id %d", ErrSubscriptionNotFound, id)
+				}
+				return err
+			}
+			records = append(records, record)
+		}
+
+		sort.Slice(records, func(i, j int) bool {
+			return records[i].StartDate.Before(records[j].StartDate)
+		})
+
+		first := records[0]
+		periodEnd := first.EndDate
+		for _, record := range records[1:] {
+			if record.UserID != first.UserID || record.ServiceName != first.ServiceName {
+				return fmt.Errorf("%w: records belong to different users or services", ErrMergeConflict)
+			}
+			if !sameCostCenter(record.CostCenter, first.CostCenter) {
+				return fmt.Errorf("%w: records belong to different cost centers", ErrMergeConflict)
+			}
+			if !record.SameMembers(first) {
+				return fmt.Errorf("%w: records are shared between different members", ErrMergeConflict)
+			}
+			if record.Price != first.Price {
+				return fmt.Errorf("%w: records have different prices, split them instead", ErrMergeConflict)
+			}
+			if record.BillingDay != first.BillingDay {
+				return fmt.Errorf("%w: records are billed on different days", ErrMergeConflict)
+			}
+			if periodEnd != nil && record.StartDate.After(periodEnd.AddDate(0, 0, 1)) {
+				return fmt.Errorf("%w: there is a gap between records before %d", ErrMergeConflict, record.ID)
+			}
+			if periodEnd != nil && (record.EndDate == nil || record.EndDate.After(*periodEnd)) {
+				periodEnd = record.EndDate
+			}
+		}
+
+		merged = first
+		var tags []string
+		for _, record := range records {
+			if record.ID < merged.ID {
+				merged = record
+			}
+			tags = append(tags, record.Tags...)
+		}
+
+		for _, record := range records {
+			if record.ID == merged.ID {
+				continue
+			}
+			if err := deleteSubscription(ctx, tx, record.ID); err != nil {
+				return err
+			}
+		}
+
+		merged.StartDate = first.StartDate
+		merged.EndDate = periodEnd
+		merged.Tags = models.NormalizeTags(tags)
+
+		return r.updateSubscription(ctx, tx, merged)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// Split ends record the day before given date and creates record continuing it from that date
+// till the original end on the same billing day, optionally with new price
+func (r *SubscriptionRepo) Split(ctx context.Context, id int, from time.Time, price *int) ([]*models.Subscription, error) {
+	var parts []*models.Subscription
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		original, err := getSubscriptionForUpdate(ctx, tx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: id %d", ErrSubscriptionNotFound, id)
+			}
+			return err
+		}
+
+		if !from.After(original.StartDate) || (original.EndDate != nil && from.After(*original.EndDate)) {
+			return fmt.Errorf("%w: month must be after start and not after end of record", ErrInvalidSplit)
+		}
+
+		continuation := &models.Subscription{
+			ServiceName: original.ServiceName,
+			Price:       original.Price,
+			UserID:      original.UserID,
+			StartDate:   from,
+			EndDate:     original.EndDate,
+			BillingDay:  original.BillingDay,
+			AutoRenew:   original.AutoRenew,
+			Members:     original.Members,
+			Tags:        original.Tags,
+			CostCenter:  original.CostCenter,
+		}
+		if price != nil {
+			continuation.Price = *price
+		}
+
+		previousDay := from.AddDate(0, 0, -1)
+		original.EndDate = &previousDay
+		original.AutoRenew = false
+
+		if err := r.updateSubscription(ctx, tx, original); err != nil {
+			return err
+		}
+
+		if err := r.createSubscription(ctx, tx, continuation); err != nil {
+			return err
+		}
+
+		parts = []*models.Subscription{original, continuation}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parts, nil
+}
+
+func getSubscriptionForUpdate(ctx context.Context, tx *sql.Tx, id int) (*models.Subscription, error) {
+	query := `
+		SELECT
+			id,
+			service_name,
+			price,
+			user_id,
+			start_date,
+			end_date,
+			tags,
+			cost_center,
+			billing_day,
+			status,
+			auto_renew
+		FROM
+			subscription_record
+		WHERE
+			id = $1
+		FOR UPDATE
+	`
+
+	subscription, err := scanSubscription(tx.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadMembers(ctx, tx, []*models.Subscription{subscription}, nil); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func sameCostCenter(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/requestmeta"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// outboxWriteLockKey serializes transactions writing events, so sequence numbers are committed in order
+	// and relay never skips over an event committed later with lower sequence. It is held from the first
+	// event written until commit, so all writes of subscription records queue on it for that time and
+	// transactions changing many records, e.g. import or bulk tagging, block the others for the whole batch.
+	// Events are therefore written after checks and changes of each record, never before them
+	outboxWriteLockKey = 7301001
+	// outboxRelayLockKey lets only one relay instance publish at a time
+	outboxRelayLockKey = 7301002
+)
+
+type OutboxRepositoryInterface interface {
+	PublishPending(ctx context.Context, limit int, publish func(*models.Event) error) (int, error)
+	ListSince(ctx context.Context, afterSequence int64, limit int) ([]*models.Event, error)
+	LastSequence(ctx context.Context) (int64, error)
+}
+
+type OutboxRepo struct {
+	db *sql.DB
+}
+
+func NewOutboxRepo(db *sql.DB) OutboxRepositoryInterface {
+	return &OutboxRepo{db: db}
+}
+
+// PublishPending passes unpublished events to publish in sequence order and marks them published.
+// It stops at the first failed event, so it is retried before any later one. Events are marked in the same
+// transaction after publishing, so an event may be published again if transaction fails (at-least-once delivery)
+func (r *OutboxRepo) PublishPending(ctx context.Context, limit int, publish func(*models.Event) error) (int, error) {
+	published := 0
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var locked bool
+		if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", outboxRelayLockKey).Scan(&locked); err != nil {
+			return fmt.Errorf("Failed to acquire outbox relay lock: %v", err)
+		}
+		if !locked {
+			return nil
+		}
+
+		query := `
+			SELECT
+				seq,
+				type,
+				subscription_id,
+				payload,
+				created_at
+			FROM
+				subscription_event
+			WHERE
+				published_at IS NULL
+			ORDER BY
+				seq
+			LIMIT $1
+		`
+
+		events, err := queryEvents(ctx, tx, query, limit)
+		if err != nil {
+			return err
+		}
+
+		var publishErr error
+		var lastSequence int64
+		for _, event := range events {
+			if publishErr = publish(event); publishErr != nil {
+				break
+			}
+			lastSequence = event.Sequence
+			published++
+		}
+
+		if published > 0 {
+			_, err := tx.ExecContext(ctx, `
+				UPDATE subscription_event
+				SET published_at = now()
+				WHERE published_at IS NULL AND seq <= $1
+			`, lastSequence)
+			if err != nil {
+				return fmt.Errorf("Failed to mark events as published: %v", err)
+			}
+		}
+
+		if publishErr != nil && published == 0 {
+			return fmt.Errorf("Failed to publish event: %v", publishErr)
+		}
+
+		return nil
+	})
+
+	return published, err
+}
+
+// ListSince returns events with sequence greater than afterSequence, oldest first
+func (r *OutboxRepo) ListSince(ctx context.Context, afterSequence int64, limit int) ([]*models.Event, error) {
+	query := `
+		SELECT
+			seq,
+			type,
+			subscription_id,
+			payload,
+			created_at
+		FROM
+			subscription_event
+		WHERE
+			seq > $2
+		ORDER BY
+			seq
+		LIMIT $1
+	`
+
+	return queryEvents(ctx, r.db, query, limit, afterSequence)
+}
+
+func (r *OutboxRepo) LastSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(seq), 0) FROM subscription_event").Scan(&sequence); err != nil {
+		return 0, fmt.Errorf("Failed to get last event sequence: %v", err)
+	}
+
+	return sequence, nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func queryEvents(ctx context.Context, q queryer, query string, args ...any) ([]*models.Event, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.Event
+	for rows.Next() {
+		var event models.Event
+
+		err := rows.Scan(
+			&event.Sequence,
+			&event.Type,
+			&event.SubscriptionID,
+			&event.Payload,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan event: %v", err)
+		}
+
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while reading events: %v", err)
+	}
+
+	return events, nil
+}
+
+// writeEvent adds event to outbox within tx. It takes outboxWriteLockKey, so it is called after the change
+// it reports, to keep other writers waiting as short as possible
+func writeEvent(ctx context.Context, tx *sql.Tx, eventType string, subscriptionID int, eventPayload any) error {
+	payload, err := json.Marshal(eventPayload)
+	if err != nil {
+		return fmt.Errorf("Failed to marshal event payload: %v", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", outboxWriteLockKey); err != nil {
+		return fmt.Errorf("Failed to acquire outbox write lock: %v", err)
+	}
+
+	query := `
+		INSERT INTO
+			subscription_event (
+				type,
+				subscription_id,
+				payload
+			)
+		VALUES
+			($1, $2, $3)
+	`
+
+	if _, err := tx.ExecContext(ctx, query, eventType, subscriptionID, string(payload)); err != nil {
+		return fmt.Errorf("Failed to write event: %v", err)
+	}
+
+	return nil
+}
+
+// recordChange writes audit entry and outbox events about change of subscription record
+// within the transaction that made the change
+func recordChange(ctx context.Context, tx *sql.Tx, operation string, subscriptionID int, before, after []byte) error {
+	if err := writeAudit(ctx, tx, operation, subscriptionID, before, after); err != nil {
+		return err
+	}
+
+	var eventTypes []string
+	switch operation {
+	case models.AuditOperationCreate:
+		eventTypes = []string{models.EventSubscriptionCreated}
+	case models.AuditOperationUpdate:
+		eventTypes = []string{models.EventSubscriptionUpdated}
+		if !hasEndDate(before) && hasEndDate(after) {
+			eventTypes = append(eventTypes, models.EventSubscriptionEnded)
+		}
+	case models.AuditOperationDelete:
+		eventTypes = []string{models.EventSubscriptionDeleted}
+	}
+
+	payload := models.EventPayload{
+		Actor:     requestmeta.Actor(ctx),
+		RequestID: requestmeta.RequestID(ctx),
+		Before:    before,
+		After:     after,
+	}
+
+	for _, eventType := range eventTypes {
+		if err := writeEvent(ctx, tx, eventType, subscriptionID, payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hasEndDate(snapshot []byte) bool {
+	var record struct {
+		EndDate *string `json:"end_date"`
+	}
+	if err := json.Unmarshal(snapshot, &record); err != nil {
+		return false
+	}
+
+	return record.EndDate != nil
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrRenewalRunning = errors.New("Renewal job is already running on another instance")
+
+type RenewalRepositoryInterface interface {
+	Run(ctx context.Context, startedAt time.Time) (*models.RenewalRun, error)
+	RecordFailure(ctx context.Context, startedAt time.Time, cause error) error
+	ListRuns(ctx context.Context, limit int) ([]*models.RenewalRun, error)
+}
+
+type RenewalRepo struct {
+	db *sql.DB
+}
+
+func NewRenewalRepo(db *sql.DB) RenewalRepositoryInterface {
+	return &RenewalRepo{db: db}
+}
+
+// Run extends every auto-renewing subscription record whose end date has passed by one billing period
+// and marks other such records as ended. Record whose extension would overlap another record of the same
+// user and service is not extended, as that period would be charged twice: its auto-renew is turned off,
+// it is ended and reported among conflicting ones. Extensions raise budget alerts like any other write.
+// Every change is audited and emitted as event. Instances are serialized by advisory lock, if another one
+// holds it nothing is done and ErrRenewalRunning is returned
+func (r *RenewalRepo) Run(ctx context.Context, startedAt time.Time) (*models.RenewalRun, error) {
+	run := &models.RenewalRun{StartedAt: startedAt}
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var acquired bool
+		if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock(hashtext('subscription_renewal'))").Scan(&acquired); err != nil {
+			return fmt.Errorf("Failed to acquire renewal lock: %v", err)
+		}
+		if !acquired {
+			return ErrRenewalRunning
+		}
+
+		renewed, err := dueSubscriptionIDs(ctx, tx, true)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range renewed {
+			extended, err := renewSubscription(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+
+			if extended {
+				run.Renewed++
+			} else {
+				run.ConflictingIDs = append(run.ConflictingIDs, int64(id))
+			}
+		}
+
+		ended, err := dueSubscriptionIDs(ctx, tx, false)
+		if err != nil {
+			return err
+		}
+
+		endQuery := `UPDATE subscription_record SET status = '` + models.SubscriptionStatusEnded + `' WHERE id = $1`
+		for _, id := range ended {
+			if err := changeSubscription(ctx, tx, id, endQuery); err != nil {
+				return err
+			}
+		}
+
+		run.Ended = len(ended)
+
+		query := `
+			INSERT INTO
+				renewal_run (
+					started_at,
+					ended,
+					renewed,
+					conflicting_ids
+				)
+			VALUES
+				($1, $2, $3, COALESCE($4::int[], '{}'))
+			RETURNING id, finished_at
+		`
+
+		return tx.QueryRowContext(ctx, query, run.StartedAt, run.Ended, run.Renewed, pq.Array(run.ConflictingIDs)).Scan(&run.ID, &run.FinishedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// RecordFailure records run that failed with cause, as failed run changes nothing
+func (r *RenewalRepo) RecordFailure(ctx context.Context, startedAt time.Time, cause error) error {
+	query := `
+		INSERT INTO
+			renewal_run (
+				started_at,
+				error
+			)
+		VALUES
+			($1, $2)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, startedAt, cause.Error()); err != nil {
+		return fmt.Errorf("Failed to record renewal run: %v", err)
+	}
+
+	return nil
+}
+
+func (r *RenewalRepo) ListRuns(ctx context.Context, limit int) ([]*models.RenewalRun, error) {
+	query := `
+		SELECT
+			id,
+			started_at,
+			finished_at,
+			ended,
+			renewed,
+			conflicting_ids,
+			error
+		FROM
+			renewal_run
+		ORDER BY
+			started_at DESC,
+			id DESC
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*models.RenewalRun
+	for rows.Next() {
+		var run models.RenewalRun
+		if err := rows.Scan(&run.ID, &run.StartedAt, &run.FinishedAt, &run.Ended, &run.Renewed, pq.Array(&run.ConflictingIDs), &run.Error); err != nil {
+			return nil, fmt.Errorf("Failed to scan renewal run: %v", err)
+		}
+
+		runs = append(runs, &run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing renewal runs: %v", err)
+	}
+
+	return runs, nil
+}
+
+// dueSubscriptionIDs locks active or auto-renewing records whose end date has passed
+func dueSubscriptionIDs(ctx context.Context, tx *sql.Tx, autoRenew bool) ([]int, error) {
+	query := `
+		SELECT
+			id
+		FROM
+			subscription_record
+		WHERE
+			end_date < current_date
+			AND auto_renew = $1
+			AND ($1 OR status = '` + models.SubscriptionStatusActive + `')
+		ORDER BY
+			id
+		FOR UPDATE
+	`
+
+	rows, err := tx.QueryContext(ctx, query, autoRenew)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find subscription records due: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("Failed to scan subscription record id: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// renewSubscription extends record with id by one billing period unless the extension overlaps another record
+// of the same user and service, then record is ended with auto-renew turned off. Reports whether it was extended
+func renewSubscription(ctx context.Context, tx *sql.Tx, id int) (bool, error) {
+	subscription, err := getSubscriptionForUpdate(ctx, tx, id)
+	if err != nil {
+		return false, err
+	}
+
+	// The new end is the day before the second billing day after the old one, so the record
+	// covers the whole period paid on the first of them
+	endQuery := `
+		SELECT
+			charge - 1
+		FROM
+			subscription_charge_dates($1::date, NULL, $2, $3::date + 1, $3::date + 62) AS charge
+		ORDER BY
+			charge
+		OFFSET 1
+		LIMIT 1
+	`
+
+	var end time.Time
+	if err := tx.QueryRowContext(ctx, endQuery, subscription.StartDate, subscription.BillingDay, subscription.EndDate).Scan(&end); err != nil {
+		return false, fmt.Errorf("Failed to compute renewed end of subscription record %d: %v", id, err)
+	}
+
+	previousEnd := subscription.EndDate
+	subscription.EndDate = &end
+
+	// Only the extension is checked, so records already overlapping before are renewed as before
+	extension := *subscription
+	extensionStart := previousEnd.AddDate(0, 0, 1)
+	extension.StartDate = extensionStart
+	if err := rejectOverlap(ctx, tx, &extension); err != nil {
+		if !errors.Is(err, ErrOverlappingSubscription) {
+			return false, err
+		}
+
+		stopQuery := `UPDATE subscription_record SET auto_renew = false, status = '` + models.SubscriptionStatusEnded + `' WHERE id = $1`
+		return false, changeSubscription(ctx, tx, id, stopQuery)
+	}
+
+	budgets, err := coveringBudgets(ctx, tx, subscription)
+	if err != nil {
+		return false, err
+	}
+
+	renewQuery := `UPDATE subscription_record SET end_date = $2, status = '` + models.SubscriptionStatusActive + `' WHERE id = $1`
+	if err := changeSubscription(ctx, tx, id, renewQuery, end); err != nil {
+		return false, err
+	}
+
+	return true, alertBudgets(ctx, tx, subscription, budgets)
+}
+
+// changeSubscription runs update query of record with id in placeholder $1, followed by args,
+// and records the change
+func changeSubscription(ctx context.Context, tx *sql.Tx, id int, query string, args ...any) error {
+	before, err := snapshotSubscription(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query, append([]any{id}, args...)...); err != nil {
+		return fmt.Errorf("Failed to change subscription record %d: %v", id, err)
+	}
+
+	after, err := snapshotSubscription(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	return recordChange(ctx, tx, models.AuditOperationUpdate, id, before, after)
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type ServiceRepositoryInterface interface {
+	List(ctx context.Context, category string) ([]*models.ServiceCategory, error)
+	SetCategory(ctx context.Context, serviceName, category string) (*models.ServiceCategory, error)
+	DeleteCategory(ctx context.Context, serviceName string) error
+}
+
+type ServiceRepo struct {
+	db *sql.DB
+}
+
+func NewServiceRepo(db *sql.DB) ServiceRepositoryInterface {
+	return &ServiceRepo{db: db}
+}
+
+// serviceCategoryQuery lists every service either having subscription records or category,
+// so services added with new records show up as uncategorized until category is assigned
+const serviceCategoryQuery = `
+	SELECT
+		service.service_name,
+		COALESCE(c.category, '` + models.UncategorizedCategory + `'),
+		(SELECT COUNT(*) FROM subscription_record s WHERE s.service_name = service.service_name)
+	FROM
+		(
+			SELECT service_name FROM subscription_record
+			UNION
+			SELECT service_name FROM service_category
+		) service
+		LEFT JOIN service_category c ON c.service_name = service.service_name
+`
+
+func (r *ServiceRepo) List(ctx context.Context, category string) ([]*models.ServiceCategory, error) {
+	query := serviceCategoryQuery + `
+		WHERE
+			$1 = '' OR COALESCE(c.category, '` + models.UncategorizedCategory + `') = $1
+		ORDER BY
+			service.service_name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var services []*models.ServiceCategory
+	for rows.Next() {
+		var service models.ServiceCategory
+		if err := rows.Scan(&service.ServiceName, &service.Category, &service.Subscriptions); err != nil {
+			return nil, fmt.Errorf("Failed to scan service: %v", err)
+		}
+
+		services = append(services, &service)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing services: %v", err)
+	}
+
+	return services, nil
+}
+
+func (r *ServiceRepo) SetCategory(ctx context.Context, serviceName, category string) (*models.ServiceCategory, error) {
+	query := `
+		INSERT INTO
+			service_category (
+				service_name,
+				category
+			)
+		VALUES
+			($1, $2)
+		ON CONFLICT (service_name) DO UPDATE
+		SET
+			category = EXCLUDED.category,
+			updated_at = now()
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, serviceName, category); err != nil {
+		return nil, err
+	}
+
+	var service models.ServiceCategory
+	err := r.db.QueryRowContext(ctx, serviceCategoryQuery+" WHERE service.service_name = $1", serviceName).Scan(
+		&service.ServiceName,
+		&service.Category,
+		&service.Subscriptions,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Error while scanning service: %v", err)
+	}
+
+	return &service, nil
+}
+
+func (r *ServiceRepo) DeleteCategory(ctx context.Context, serviceName string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM service_category WHERE service_name = $1", serviceName)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("No category assigned to service: %s", serviceName)
+	}
+
+	return nil
+}
@@ -1,31 +1,119 @@
 package repository
 
 import (
+	"Effective-Mobile-Test/internal/config"
 	"Effective-Mobile-Test/internal/models"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type RepositoryInterface interface {
 	Create(ctx context.Context, subscription *models.Subscription) error
+	CreateBatch(ctx context.Context, subscriptions []*models.Subscription, atomic bool) []error
 	GetByID(ctx context.Context, id int) (*models.Subscription, error)
+	Duplicates(ctx context.Context, subscriptions []*models.Subscription) ([]bool, error)
+	ListOverlaps(ctx context.Context, userID *uuid.UUID, serviceName string) ([]*models.Overlap, error)
+	Merge(ctx context.Context, ids []int) (*models.Subscription, error)
+	Split(ctx context.Context, id int, from time.Time, price *int) ([]*models.Subscription, error)
 	Update(ctx context.Context, subscription *models.Subscription) (*models.Subscription, error)
 	DeleteByID(ctx context.Context, id int) error
-	List(ctx context.Context) ([]*models.Subscription, error)
+	List(ctx context.Context, filter *models.SubscriptionFilter) ([]*models.Subscription, error)
+	Stream(ctx context.Context, filter *models.SubscriptionFilter, fn func(*models.Subscription) error) error
 	CalculateSubscriptionCost(ctx context.Context, subscriptionCost *models.SubscriptionCost) (int, error)
+	CalculateSubscriptionCostBreakdown(ctx context.Context, subscriptionCost *models.SubscriptionCost, groupBy string) ([]*models.SubscriptionCostItem, error)
+	Forecast(ctx context.Context, from time.Time, months int) ([]*models.ForecastMonth, error)
+	UserSpend(ctx context.Context, userID uuid.UUID, month time.Time) (int, int, error)
 }
 
+const streamChunkSize = 500
+
+var ErrBatchRolledBack = errors.New("Subscription record was not created because another record of the batch failed")
+
+var ErrOverlappingSubscription = errors.New("Subscription record overlaps another record of the same user and service")
+
+var ErrSubscriptionNotFound = errors.New("Subscription record not found")
+
 type SubscriptionRepo struct {
-	db *sql.DB
+	db                 *sql.DB
+	strictOverlap      bool
+	autoProvisionUsers bool
 }
 
-func NewSubscriptionRepo(db *sql.DB) RepositoryInterface {
-	return &SubscriptionRepo{db: db}
+func NewSubscriptionRepo(db *sql.DB, cfg *config.Config) RepositoryInterface {
+	return &SubscriptionRepo{
+		db:                 db,
+		strictOverlap:      cfg.StrictOverlap,
+		autoProvisionUsers: cfg.AutoProvisionUsers,
+	}
 }
 
 func (r *SubscriptionRepo) Create(ctx context.Context, subscription *models.Subscription) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		return r.createSubscription(ctx, tx, subscription)
+	})
+}
+
+// CreateBatch creates every subscription record and returns per-item errors in the same order.
+// In atomic mode records are inserted in one transaction and none is created if any of them fails,
+// otherwise every record is inserted independently
+func (r *SubscriptionRepo) CreateBatch(ctx context.Context, subscriptions []*models.Subscription, atomic bool) []error {
+	errs := make([]error, len(subscriptions))
+
+	if !atomic {
+		for i, subscription := range subscriptions {
+			errs[i] = r.Create(ctx, subscription)
+		}
+		return errs
+	}
+
+	failed := -1
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		for i, subscription := range subscriptions {
+			if err := r.createSubscription(ctx, tx, subscription); err != nil {
+				failed = i
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		return errs
+	}
+
+	for i, subscription := range subscriptions {
+		subscription.ID = 0
+		if i == failed || failed == -1 {
+			errs[i] = err
+		} else {
+			errs[i] = ErrBatchRolledBack
+		}
+	}
+
+	return errs
+}
+
+func (r *SubscriptionRepo) createSubscription(ctx context.Context, tx *sql.Tx, subscription *models.Subscription) error {
+	if err := r.checkOverlap(ctx, tx, subscription); err != nil {
+		return err
+	}
+
+	if r.autoProvisionUsers {
+		if err := provisionUser(ctx, tx, subscription.UserID); err != nil {
+			return err
+		}
+	}
+
+	budgets, err := coveringBudgets(ctx, tx, subscription)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO
 			subscription_record (
@@ -33,14 +121,22 @@ func (r *SubscriptionRepo) Create(ctx context.Context, subscription *models.Subs
 				price,
 				user_id,
 				start_date,
-				end_date
+				end_date,
+				tags,
+				cost_center,
+				billing_day,
+				auto_renew,
+				status
 			)
 		VALUES
-			($1, $2, $3, $4, $5)
-		RETURNING id
+			(
+				$1, $2, $3, $4, $5, COALESCE($6::text[], '{}'), NULLIF($7, ''), COALESCE(NULLIF($8, 0), EXTRACT(DAY FROM $4::date)), $9,
+				` + statusOf("$5") + `
+			)
+		RETURNING id, tags, cost_center, billing_day, status
 	`
 
-	err := r.db.QueryRowContext(
+	err = tx.QueryRowContext(
 		ctx,
 		query,
 		subscription.ServiceName,
@@ -48,15 +144,173 @@ func (r *SubscriptionRepo) Create(ctx context.Context, subscription *models.Subs
 		subscription.UserID,
 		subscription.StartDate,
 		subscription.EndDate,
-	).Scan(&subscription.ID)
+		pq.Array(subscription.Tags),
+		subscription.CostCenter,
+		subscription.BillingDay,
+		subscription.AutoRenew,
+	).Scan(&subscription.ID, pq.Array(&subscription.Tags), &subscription.CostCenter, &subscription.BillingDay, &subscription.Status)
+	if err != nil {
+		if isViolation(err, foreignKeyViolation) {
+			return ErrUnknownUser
+		}
+		return err
+	}
+
+	if err := r.writeMembers(ctx, tx, subscription); err != nil {
+		return err
+	}
+
+	if err := alertBudgets(ctx, tx, subscription, budgets); err != nil {
+		return err
+	}
 
+	after, err := snapshotSubscription(ctx, tx, subscription.ID)
 	if err != nil {
 		return err
 	}
 
+	return recordChange(ctx, tx, models.AuditOperationCreate, subscription.ID, nil, after)
+}
+
+// checkOverlap rejects subscription record overlapping another record of the same user and service
+// when strict overlap mode is on
+func (r *SubscriptionRepo) checkOverlap(ctx context.Context, tx *sql.Tx, subscription *models.Subscription) error {
+	if !r.strictOverlap {
+		return nil
+	}
+
+	return rejectOverlap(ctx, tx, subscription)
+}
+
+// rejectOverlap returns ErrOverlappingSubscription if subscription record overlaps another record of the same
+// user and service. Records of the pair are serialized by advisory lock, so concurrent transactions can't both
+// pass the check
+func rejectOverlap(ctx context.Context, tx *sql.Tx, subscription *models.Subscription) error {
+	_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1::text || '/' || $2))", subscription.UserID, subscription.ServiceName)
+	if err != nil {
+		return fmt.Errorf("Failed to lock subscription records of user and service: %v", err)
+	}
+
+	query := `
+		SELECT EXISTS (
+			SELECT
+				1
+			FROM
+				subscription_record
+			WHERE
+				user_id = $1
+				AND service_name = $2
+				AND id <> $3
+				AND start_date <= COALESCE($5::date, 'infinity')
+				AND $4::date <= COALESCE(end_date, 'infinity')
+		)
+	`
+
+	var overlaps bool
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		subscription.UserID,
+		subscription.ServiceName,
+		subscription.ID,
+		subscription.StartDate,
+		subscription.EndDate,
+	).Scan(&overlaps)
+	if err != nil {
+		return fmt.Errorf("Failed to check overlapping subscription records: %v", err)
+	}
+
+	if overlaps {
+		return ErrOverlappingSubscription
+	}
+
 	return nil
 }
 
+// ListOverlaps finds pairs of records of the same user and service whose periods overlap.
+// Charges made twice are billing dates within the overlap of the record charged less often,
+// open-ended overlap is counted up to the current date
+func (r *SubscriptionRepo) ListOverlaps(ctx context.Context, userID *uuid.UUID, serviceName string) ([]*models.Overlap, error) {
+	query := `
+		SELECT
+			first_id,
+			second_id,
+			user_id,
+			service_name,
+			overlap_start,
+			overlap_end,
+			charges,
+			charges * lower_price
+		FROM (
+			SELECT
+				a.id AS first_id,
+				b.id AS second_id,
+				a.user_id,
+				a.service_name,
+				period.overlap_start,
+				period.overlap_end,
+				LEAST(a.price, b.price) AS lower_price,
+				LEAST(
+					subscription_charge_count(a.start_date, a.end_date, a.billing_day, period.overlap_start, period.overlap_end),
+					subscription_charge_count(b.start_date, b.end_date, b.billing_day, period.overlap_start, period.overlap_end)
+				) AS charges
+			FROM
+				subscription_record a
+				JOIN subscription_record b ON a.user_id = b.user_id
+					AND a.service_name = b.service_name
+					AND a.id < b.id
+					AND a.start_date <= COALESCE(b.end_date, 'infinity')
+					AND b.start_date <= COALESCE(a.end_date, 'infinity')
+				CROSS JOIN LATERAL (
+					SELECT
+						GREATEST(a.start_date, b.start_date) AS overlap_start,
+						LEAST(a.end_date, b.end_date) AS overlap_end
+				) period
+			WHERE
+				($1::uuid IS NULL OR a.user_id = $1)
+				AND ($2 = '' OR a.service_name = $2)
+		) overlap
+		ORDER BY
+			user_id,
+			service_name,
+			first_id,
+			second_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overlaps []*models.Overlap
+	for rows.Next() {
+		var overlap models.Overlap
+
+		err := rows.Scan(
+			&overlap.FirstID,
+			&overlap.SecondID,
+			&overlap.UserID,
+			&overlap.ServiceName,
+			&overlap.StartDate,
+			&overlap.EndDate,
+			&overlap.Months,
+			&overlap.DoubleCharged,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan overlapping subscription records: %v", err)
+		}
+
+		overlaps = append(overlaps, &overlap)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing overlaps: %v", err)
+	}
+
+	return overlaps, nil
+}
+
 func (r *SubscriptionRepo) GetByID(ctx context.Context, id int) (*models.Subscription, error) {
 	query := `
 		SELECT
@@ -65,30 +319,132 @@ func (r *SubscriptionRepo) GetByID(ctx context.Context, id int) (*models.Subscri
 			price,
 			user_id,
 			start_date,
-			end_date
+			end_date,
+			tags,
+			cost_center,
+			billing_day,
+			status,
+			auto_renew
 		FROM
 			subscription_record
 		WHERE
 			id = $1
 	`
-	var subscription models.Subscription
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&subscription.ID,
-		&subscription.ServiceName,
-		&subscription.Price,
-		&subscription.UserID,
-		&subscription.StartDate,
-		&subscription.EndDate,
-	)
-
+	subscription, err := scanSubscription(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		return nil, err
 	}
 
-	return &subscription, nil
+	if err := loadMembers(ctx, r.db, []*models.Subscription{subscription}, nil); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// Duplicates reports which of subscription records duplicate an existing record or a record before them
+// in the slice, having the same service, price, user, start and end, in one query for the whole slice
+func (r *SubscriptionRepo) Duplicates(ctx context.Context, subscriptions []*models.Subscription) ([]bool, error) {
+	query := `
+		WITH candidate AS (
+			SELECT
+				service_name,
+				price,
+				user_id,
+				start_date,
+				NULLIF(end_date, '')::date AS end_date,
+				position
+			FROM
+				unnest($1::text[], $2::int[], $3::uuid[], $4::date[], $5::text[]) WITH ORDINALITY
+					AS candidate(service_name, price, user_id, start_date, end_date, position)
+		)
+		SELECT
+			EXISTS (
+				SELECT
+					1
+				FROM
+					subscription_record
+				WHERE
+					subscription_record.service_name = candidate.service_name
+					AND subscription_record.price = candidate.price
+					AND subscription_record.user_id = candidate.user_id
+					AND subscription_record.start_date = candidate.start_date
+					AND subscription_record.end_date IS NOT DISTINCT FROM candidate.end_date
+			)
+			OR EXISTS (
+				SELECT
+					1
+				FROM
+					candidate earlier
+				WHERE
+					earlier.position < candidate.position
+					AND earlier.service_name = candidate.service_name
+					AND earlier.price = candidate.price
+					AND earlier.user_id = candidate.user_id
+					AND earlier.start_date = candidate.start_date
+					AND earlier.end_date IS NOT DISTINCT FROM candidate.end_date
+			)
+		FROM
+			candidate
+		ORDER BY
+			position
+	`
+
+	serviceNames := make([]string, len(subscriptions))
+	prices := make([]int64, len(subscriptions))
+	userIDs := make([]string, len(subscriptions))
+	startDates := make([]string, len(subscriptions))
+	endDates := make([]string, len(subscriptions))
+	for i, subscription := range subscriptions {
+		serviceNames[i] = subscription.ServiceName
+		prices[i] = int64(subscription.Price)
+		userIDs[i] = subscription.UserID.String()
+		startDates[i] = subscription.StartDate.Format(time.DateOnly)
+		if subscription.EndDate != nil {
+			endDates[i] = subscription.EndDate.Format(time.DateOnly)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(serviceNames), pq.Array(prices), pq.Array(userIDs), pq.Array(startDates), pq.Array(endDates))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to check duplicate subscription records: %v", err)
+	}
+	defer rows.Close()
+
+	duplicates := make([]bool, 0, len(subscriptions))
+	for rows.Next() {
+		var duplicate bool
+		if err := rows.Scan(&duplicate); err != nil {
+			return nil, fmt.Errorf("Failed to scan duplicate check: %v", err)
+		}
+
+		duplicates = append(duplicates, duplicate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while checking duplicates: %v", err)
+	}
+
+	return duplicates, nil
 }
 
 func (r *SubscriptionRepo) Update(ctx context.Context, subscription *models.Subscription) (*models.Subscription, error) {
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		return r.updateSubscription(ctx, tx, subscription)
+	})
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: id %d", ErrSubscriptionNotFound, subscription.ID)
+		} else {
+			return nil, err
+		}
+	}
+
+	return subscription, nil
+}
+
+func (r *SubscriptionRepo) updateSubscription(ctx context.Context, tx *sql.Tx, subscription *models.Subscription) error {
 	query := `
 		UPDATE subscription_record
 		SET
@@ -96,17 +452,55 @@ func (r *SubscriptionRepo) Update(ctx context.Context, subscription *models.Subs
 			price = $2,
 			user_id = $3,
 			start_date = $4,
-			end_date = $5
+			end_date = $5,
+			tags = COALESCE($7::text[], '{}'),
+			cost_center = NULLIF($8, ''),
+			billing_day = COALESCE(NULLIF($9, 0), EXTRACT(DAY FROM $4::date)),
+			auto_renew = $10,
+			status = ` + statusOf("$5") + `
 		WHERE id = $6
 		RETURNING
 			service_name,
 			price,
 			user_id,
 			start_date,
-			end_date
+			end_date,
+			tags,
+			cost_center,
+			billing_day,
+			status,
+			auto_renew
 	`
 
-	err := r.db.QueryRowContext(
+	before, err := snapshotSubscription(ctx, tx, subscription.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkOverlap(ctx, tx, subscription); err != nil {
+		return err
+	}
+
+	if r.autoProvisionUsers {
+		if err := provisionUser(ctx, tx, subscription.UserID); err != nil {
+			return err
+		}
+	}
+
+	// members are kept when not given, they are loaded ahead to find budgets of the record
+	keepMembers := subscription.Members == nil
+	if keepMembers {
+		if err := loadMembers(ctx, tx, []*models.Subscription{subscription}, nil); err != nil {
+			return err
+		}
+	}
+
+	budgets, err := coveringBudgets(ctx, tx, subscription)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(
 		ctx,
 		query,
 		subscription.ServiceName,
@@ -115,49 +509,159 @@ func (r *SubscriptionRepo) Update(ctx context.Context, subscription *models.Subs
 		subscription.StartDate,
 		subscription.EndDate,
 		subscription.ID,
+		pq.Array(subscription.Tags),
+		subscription.CostCenter,
+		subscription.BillingDay,
+		subscription.AutoRenew,
 	).Scan(
 		&subscription.ServiceName,
 		&subscription.Price,
 		&subscription.UserID,
 		&subscription.StartDate,
 		&subscription.EndDate,
+		pq.Array(&subscription.Tags),
+		&subscription.CostCenter,
+		&subscription.BillingDay,
+		&subscription.Status,
+		&subscription.AutoRenew,
 	)
+	if err != nil {
+		if isViolation(err, foreignKeyViolation) {
+			return ErrUnknownUser
+		}
+		return err
+	}
+
+	if !keepMembers {
+		if err := r.writeMembers(ctx, tx, subscription); err != nil {
+			return err
+		}
+	}
+
+	if err := alertBudgets(ctx, tx, subscription, budgets); err != nil {
+		return err
+	}
+
+	after, err := snapshotSubscription(ctx, tx, subscription.ID)
+	if err != nil {
+		return err
+	}
+
+	return recordChange(ctx, tx, models.AuditOperationUpdate, subscription.ID, before, after)
+}
+
+func (r *SubscriptionRepo) DeleteByID(ctx context.Context, id int) error {
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		return deleteSubscription(ctx, tx, id)
+	})
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("Subscription record with id %d not found", subscription.ID)
-		} else {
-			return nil, err
+			return fmt.Errorf("%w: id %d", ErrSubscriptionNotFound, id)
 		}
+		return err
 	}
 
-	return subscription, nil
+	return nil
 }
 
-func (r *SubscriptionRepo) DeleteByID(ctx context.Context, id int) error {
+func deleteSubscription(ctx context.Context, tx *sql.Tx, id int) error {
 	query := `
 		DELETE FROM subscription_record
 		WHERE id = $1
 	`
 
-	res, err := r.db.ExecContext(ctx, query, id)
+	before, err := snapshotSubscription(ctx, tx, id)
 	if err != nil {
 		return err
 	}
 
-	count, err := res.RowsAffected()
-	if err != nil {
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
 		return err
 	}
 
-	if count == 0 {
-		return fmt.Errorf("No subscription record with id: %v", err)
+	return recordChange(ctx, tx, models.AuditOperationDelete, id, before, nil)
+}
+
+func (r *SubscriptionRepo) List(ctx context.Context, filter *models.SubscriptionFilter) ([]*models.Subscription, error) {
+	query, args := listQuery(filter)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return nil
+	var records []*models.Subscription
+	for rows.Next() {
+		record, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan subscription record while listing: %v", err)
+		}
+
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing: %v", err)
+	}
+
+	if err := loadMembers(ctx, r.db, records, filter.AsOf); err != nil {
+		return nil, fmt.Errorf("Failed to load subscription members: %v", err)
+	}
+
+	return records, nil
+}
+
+// Stream reads subscription records through server-side cursor in chunks and passes them to fn one by one,
+// so the whole table is never held in memory
+func (r *SubscriptionRepo) Stream(ctx context.Context, filter *models.SubscriptionFilter, fn func(*models.Subscription) error) error {
+	query, args := listQuery(filter)
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("Failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DECLARE subscription_stream NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		return fmt.Errorf("Failed to declare cursor: %v", err)
+	}
+
+	for {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("FETCH FORWARD %d FROM subscription_stream", streamChunkSize))
+		if err != nil {
+			return fmt.Errorf("Failed to fetch from cursor: %v", err)
+		}
+
+		fetched := 0
+		for rows.Next() {
+			record, err := scanSubscription(rows)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("Failed to scan subscription record while streaming: %v", err)
+			}
+			fetched++
+
+			if err := fn(record); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return fmt.Errorf("rows error while streaming: %v", err)
+		}
+		rows.Close()
+
+		if fetched < streamChunkSize {
+			return nil
+		}
+	}
 }
 
-func (r *SubscriptionRepo) List(ctx context.Context) ([]*models.Subscription, error) {
+func listQuery(filter *models.SubscriptionFilter) (string, []any) {
 	query := `
 		SELECT
 			id,
@@ -165,68 +669,304 @@ func (r *SubscriptionRepo) List(ctx context.Context) ([]*models.Subscription, er
 			price,
 			user_id,
 			start_date,
-			end_date
+			end_date,
+			tags,
+			cost_center,
+			COALESCE(billing_day, EXTRACT(DAY FROM start_date))::int,
+			COALESCE(status, '` + models.SubscriptionStatusActive + `'),
+			COALESCE(auto_renew, false)
 		FROM
-			subscription_record
+			` + subscriptionSource(filter.AsOf, "$8") + `
+		WHERE
+			(
+				$1::uuid[] IS NULL
+				OR user_id = ANY($1)
+				OR id IN (SELECT subscription_id FROM ` + subscriptionMemberSource(filter.AsOf, "$8") + ` WHERE user_id = ANY($1))
+			)
+			AND ($2::date IS NULL OR end_date IS NULL OR end_date >= $2)
+			AND ($2::date IS NULL OR start_date < $2::date + interval '1 month')
+			AND ($7::date IS NULL OR end_date IS NULL OR end_date >= $7)
+			AND ($3::text[] IS NULL OR tags @> $3)
+			AND ($4::text IS NULL OR cost_center = $4)
+			AND ($5::int IS NULL OR id > $5)
+		ORDER BY
+			id
+		LIMIT $6::int
+	`
+
+	var userIDs any
+	if filter.UserID != nil || filter.UserIDs != nil {
+		ids := make([]string, 0, len(filter.UserIDs)+1)
+		if filter.UserID != nil {
+			ids = append(ids, filter.UserID.String())
+		}
+		for _, id := range filter.UserIDs {
+			ids = append(ids, id.String())
+		}
+		userIDs = pq.Array(ids)
+	}
+
+	var tags any
+	if filter.Tags != nil {
+		tags = pq.Array(filter.Tags)
+	}
+
+	var afterID, limit any
+	if filter.AfterID > 0 {
+		afterID = filter.AfterID
+	}
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+
+	args := []any{userIDs, filter.ActiveAt, tags, filter.CostCenter, afterID, limit, filter.NotEndedBefore}
+	if filter.AsOf != nil {
+		args = append(args, *filter.AsOf)
+	}
+
+	return query, args
+}
+
+// statusOf returns SQL expression of status of record ending at date in placeholder as of today
+func statusOf(placeholder string) string {
+	return `CASE WHEN ` + placeholder + `::date < current_date THEN '` + models.SubscriptionStatusEnded + `' ELSE '` + models.SubscriptionStatusActive + `' END`
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSubscription(row rowScanner) (*models.Subscription, error) {
+	var record models.Subscription
+
+	err := row.Scan(
+		&record.ID,
+		&record.ServiceName,
+		&record.Price,
+		&record.UserID,
+		&record.StartDate,
+		&record.EndDate,
+		pq.Array(&record.Tags),
+		&record.CostCenter,
+		&record.BillingDay,
+		&record.Status,
+		&record.AutoRenew,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+func (r *SubscriptionRepo) CalculateSubscriptionCost(ctx context.Context, subscriptionCost *models.SubscriptionCost) (int, error) {
+	query, args := costQuery("COALESCE(ROUND(SUM(price)), 0)::int", "", subscriptionCost)
+
+	var totalCost int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&totalCost)
+	if err != nil {
+		return 0, fmt.Errorf("Error while scanning result: %v", err)
+	}
+
+	return totalCost, nil
+}
+
+// CalculateSubscriptionCostBreakdown groups subscription cost by service name, category, cost center or tag.
+// Records without cost center or tags fall into group with empty key, record with several tags
+// is counted in the group of each of them
+func (r *SubscriptionRepo) CalculateSubscriptionCostBreakdown(ctx context.Context, subscriptionCost *models.SubscriptionCost, groupBy string) ([]*models.SubscriptionCostItem, error) {
+	key := "service_name"
+	switch groupBy {
+	case models.CostGroupByCategory:
+		key = "category"
+	case models.CostGroupByCostCenter:
+		key = "COALESCE(cost_center, '')"
+	case models.CostGroupByTag:
+		key = "unnest(CASE WHEN COALESCE(cardinality(tags), 0) = 0 THEN ARRAY[''] ELSE tags END)"
+	case models.CostGroupBySubscription:
+		key = "id::text"
+	case models.CostGroupByUser:
+		key = "user_id::text"
+	}
+
+	charges, args := costQuery(key+" AS key, price", "", subscriptionCost)
+	query := `
+		SELECT
+			key,
+			ROUND(SUM(price))::int
+		FROM
+			(` + charges + `) charge
+		GROUP BY
+			key
+		ORDER BY
+			SUM(price) DESC,
+			key
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var records []*models.Subscription
+	var items []*models.SubscriptionCostItem
 	for rows.Next() {
-		var record models.Subscription
+		var item models.SubscriptionCostItem
+		if err := rows.Scan(&item.Key, &item.Cost); err != nil {
+			return nil, fmt.Errorf("Failed to scan subscription cost item: %v", err)
+		}
 
-		err := rows.Scan(
-			&record.ID,
-			&record.ServiceName,
-			&record.Price,
-			&record.UserID,
-			&record.StartDate,
-			&record.EndDate,
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while calculating cost breakdown: %v", err)
+	}
+
+	return items, nil
+}
+
+// Forecast projects cost of every month starting from month of from. A month is charged for every billing day
+// of records in it, so open-ended records are charged until the end of forecast, ended ones until their end date,
+// and scheduled price changes, stored as records starting in the future, from their start date
+func (r *SubscriptionRepo) Forecast(ctx context.Context, from time.Time, months int) ([]*models.ForecastMonth, error) {
+	query := `
+		WITH month AS (
+			SELECT
+				generate_series(
+					date_trunc('month', $1::date),
+					date_trunc('month', $1::date) + ($2::int - 1) * interval '1 month',
+					interval '1 month'
+				)::date AS start
 		)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to scan subscription record while listing: %v", err)
+		SELECT
+			m.start,
+			s.service_name,
+			COALESCE(SUM(s.price * ` + chargesIn + `), 0)
+		FROM
+			month m
+			LEFT JOIN subscription_record s ON s.start_date < m.start + interval '1 month'
+				AND (s.end_date IS NULL OR s.end_date >= m.start)
+		GROUP BY
+			m.start,
+			s.service_name
+		ORDER BY
+			m.start,
+			3 DESC,
+			s.service_name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, from, months)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var forecast []*models.ForecastMonth
+	for rows.Next() {
+		var month time.Time
+		var serviceName sql.NullString
+		var cost int
+
+		if err := rows.Scan(&month, &serviceName, &cost); err != nil {
+			return nil, fmt.Errorf("Failed to scan forecast: %v", err)
+		}
+
+		if len(forecast) == 0 || !forecast[len(forecast)-1].Month.Equal(month) {
+			forecast = append(forecast, &models.ForecastMonth{Month: month, Services: []models.SubscriptionCostItem{}})
 		}
 
-		records = append(records, &record)
+		if serviceName.Valid {
+			current := forecast[len(forecast)-1]
+			current.Total += cost
+			current.Services = append(current.Services, models.SubscriptionCostItem{Key: serviceName.String, Cost: cost})
+		}
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows error while listing: %v", err)
+		return nil, fmt.Errorf("rows error while forecasting: %v", err)
 	}
 
-	return records, nil
+	return forecast, nil
 }
 
-func (r *SubscriptionRepo) CalculateSubscriptionCost(ctx context.Context, subscriptionCost *models.SubscriptionCost) (int, error) {
+// UserSpend sums monthly charges of user from the beginning of year of month and from the first
+// subscription up to month inclusive, counting only user's share of shared records
+func (r *SubscriptionRepo) UserSpend(ctx context.Context, userID uuid.UUID, month time.Time) (int, int, error) {
 	query := `
+		WITH month AS (
+			SELECT
+				generate_series(
+					(SELECT date_trunc('month', MIN(start_date)) FROM ` + chargeSource(nil, "") + ` c WHERE c.user_id = $1),
+					date_trunc('month', $2::date),
+					interval '1 month'
+				)::date AS start
+		)
 		SELECT
-			COALESCE(SUM(price), 0)
+			COALESCE(ROUND(SUM(s.price * ` + chargesIn + `) FILTER (WHERE m.start >= date_trunc('year', $2::date))), 0)::int,
+			COALESCE(ROUND(SUM(s.price * ` + chargesIn + `)), 0)::int
 		FROM
-			subscription_record
+			month m
+			JOIN ` + chargeSource(nil, "") + ` s ON s.user_id = $1
+				AND ` + activeIn + `
+	`
+
+	var yearSpend, lifetimeTotal int
+	err := r.db.QueryRowContext(ctx, query, userID, month).Scan(&yearSpend, &lifetimeTotal)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Error while scanning user spend: %v", err)
+	}
+
+	return yearSpend, lifetimeTotal, nil
+}
+
+// costQuery builds query over subscription records matching cost filtering parameters, with price
+// being charged on every billing day of record between $1 and $2, from its start up to the current date
+// if they are not set
+func costQuery(selectList, tail string, subscriptionCost *models.SubscriptionCost) (string, []any) {
+	query := `
+		SELECT
+			` + selectList + `
+		FROM
+			(
+				SELECT
+					id,
+					service_name,
+					start_date,
+					end_date,
+					tags,
+					cost_center,
+					category,
+					user_id,
+					price * subscription_charge_count(start_date, end_date, billing_day, $1::date, $2::date) AS price
+				FROM
+					` + chargeSource(subscriptionCost.AsOf, "$7") + ` AS subscription_charge
+			) AS subscription_charge
 		WHERE
 			($2::date IS NULL OR start_date <= $2)
-    		AND ($1::date IS NULL OR end_date IS NULL OR end_date >= $1)
+			AND ($1::date IS NULL OR end_date IS NULL OR end_date >= $1)
 			AND (user_id = $3 OR $3 IS NULL)
 			AND (service_name = $4 OR $4 IS NULL)
-	`
-	
-	var totalCost int
-	err := r.db.QueryRowContext(
-		ctx,
-		query,
+			AND (category = $5 OR $5 IS NULL)
+			AND ($6::int[] IS NULL OR id = ANY($6))
+		` + tail
+
+	var ids any
+	if subscriptionCost.IDs != nil {
+		ids = pq.Array(subscriptionCost.IDs)
+	}
+
+	args := []any{
 		subscriptionCost.StartDate,
 		subscriptionCost.EndDate,
 		subscriptionCost.UserID,
 		subscriptionCost.ServiceName,
-	).Scan(&totalCost)
-	if err != nil {
-		return 0, fmt.Errorf("Error while scanning result: %v", err)
+		subscriptionCost.Category,
+		ids,
+	}
+	if subscriptionCost.AsOf != nil {
+		args = append(args, *subscriptionCost.AsOf)
 	}
 
-	return totalCost, nil
+	return query, args
 }
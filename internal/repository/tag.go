@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+type TagRepositoryInterface interface {
+	List(ctx context.Context) ([]*models.TagCount, error)
+	Merge(ctx context.Context, from []string, to string) (int, error)
+}
+
+type TagRepo struct {
+	db *sql.DB
+}
+
+func NewTagRepo(db *sql.DB) TagRepositoryInterface {
+	return &TagRepo{db: db}
+}
+
+func (r *TagRepo) List(ctx context.Context) ([]*models.TagCount, error) {
+	query := `
+		SELECT
+			tag,
+			COUNT(*)
+		FROM
+			subscription_record
+			CROSS JOIN LATERAL unnest(tags) tag
+		GROUP BY
+			tag
+		ORDER BY
+			tag
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*models.TagCount
+	for rows.Next() {
+		var tag models.TagCount
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
+			return nil, fmt.Errorf("Failed to scan tag: %v", err)
+		}
+
+		tags = append(tags, &tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing tags: %v", err)
+	}
+
+	return tags, nil
+}
+
+// Merge replaces tags from with tag to on every subscription record keeping position of the first
+// replaced tag, so renaming is merge of a single tag. Every changed record is audited and emitted as event
+func (r *TagRepo) Merge(ctx context.Context, from []string, to string) (int, error) {
+	query := `
+		UPDATE subscription_record
+		SET
+			tags = ARRAY(
+				SELECT
+					tag
+				FROM
+					(
+						SELECT
+							CASE WHEN tag = ANY($2) THEN $3 ELSE tag END AS tag,
+							ordinal
+						FROM
+							unnest(tags) WITH ORDINALITY AS t(tag, ordinal)
+					) replaced
+				GROUP BY
+					tag
+				ORDER BY
+					MIN(ordinal)
+			)
+		WHERE id = $1
+	`
+
+	updated := 0
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		ids, err := taggedSubscriptionIDs(ctx, tx, from)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			before, err := snapshotSubscription(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.ExecContext(ctx, query, id, pq.Array(from), to); err != nil {
+				return fmt.Errorf("Failed to replace tags of subscription record %d: %v", id, err)
+			}
+
+			after, err := snapshotSubscription(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+
+			if err := recordChange(ctx, tx, models.AuditOperationUpdate, id, before, after); err != nil {
+				return err
+			}
+		}
+
+		updated = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return updated, nil
+}
+
+func taggedSubscriptionIDs(ctx context.Context, tx *sql.Tx, tags []string) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM subscription_record WHERE tags && $1 ORDER BY id FOR UPDATE", pq.Array(tags))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("Failed to scan subscription record id: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,390 @@
+package repository
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type UserRepositoryInterface interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	Update(ctx context.Context, user *models.User) (*models.User, error)
+	DeleteByID(ctx context.Context, id uuid.UUID, mode string) (int, error)
+	List(ctx context.Context) ([]*models.User, error)
+	ListByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error)
+}
+
+var ErrUserNotFound = errors.New("User not found")
+
+var ErrUserExists = errors.New("User with the same id or email already exists")
+
+var ErrUnknownUser = errors.New("User does not exist, create it first")
+
+var ErrUserHasSubscriptions = errors.New("User has or shares subscription records, remove them first or delete with cascade=soft_delete")
+
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+type UserRepo struct {
+	db *sql.DB
+}
+
+func NewUserRepo(db *sql.DB) UserRepositoryInterface {
+	return &UserRepo{db: db}
+}
+
+func (r *UserRepo) Create(ctx context.Context, user *models.User) error {
+	query := `
+		INSERT INTO
+			users (
+				id,
+				name,
+				email
+			)
+		VALUES
+			(COALESCE($1, gen_random_uuid()), $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+
+	var id *uuid.UUID
+	if user.ID != uuid.Nil {
+		id = &user.ID
+	}
+
+	err := r.db.QueryRowContext(ctx, query, id, user.Name, user.Email).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if isViolation(err, uniqueViolation) {
+			return ErrUserExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *UserRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	query := `
+		SELECT
+			id,
+			name,
+			email,
+			created_at,
+			updated_at
+		FROM
+			users
+		WHERE
+			id = $1
+	`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *UserRepo) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	query := `
+		UPDATE users
+		SET
+			name = $1,
+			email = $2,
+			updated_at = now()
+		WHERE id = $3
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, user.Name, user.Email, user.ID).Scan(&user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		if isViolation(err, uniqueViolation) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// DeleteByID removes user with budgets. In block mode user with subscription records or sharing records
+// of others is not removed. In soft delete mode records are moved to deleted_subscription_record and deleted,
+// user is removed from members of shared records, both with the usual audit and change events.
+// Returns number of soft deleted records
+func (r *UserRepo) DeleteByID(ctx context.Context, id uuid.UUID, mode string) (int, error) {
+	var deleted int
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// Lock conflicts with foreign key checks, so no record is created for user meanwhile
+		var exists bool
+		err := tx.QueryRowContext(ctx, "SELECT true FROM users WHERE id = $1 FOR UPDATE", id).Scan(&exists)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		ids, err := userSubscriptionIDs(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		sharedIDs, err := userSharedSubscriptionIDs(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if (len(ids) > 0 || len(sharedIDs) > 0) && mode != models.UserDeleteSoftDelete {
+			return ErrUserHasSubscriptions
+		}
+
+		for _, subscriptionID := range sharedIDs {
+			if err := removeMember(ctx, tx, subscriptionID, id); err != nil {
+				return err
+			}
+		}
+
+		archiveQuery := `
+			INSERT INTO
+				deleted_subscription_record (
+					id,
+					user_id,
+					record
+				)
+			SELECT
+				s.id,
+				s.user_id,
+				to_jsonb(s)
+			FROM
+				subscription_record s
+			WHERE
+				s.id = $1
+		`
+
+		for _, subscriptionID := range ids {
+			if _, err := tx.ExecContext(ctx, archiveQuery, subscriptionID); err != nil {
+				return fmt.Errorf("Failed to archive subscription record %d: %v", subscriptionID, err)
+			}
+
+			if err := deleteSubscription(ctx, tx, subscriptionID); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", id); err != nil {
+			return err
+		}
+
+		deleted = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+func (r *UserRepo) List(ctx context.Context) ([]*models.User, error) {
+	query := `
+		SELECT
+			id,
+			name,
+			email,
+			created_at,
+			updated_at
+		FROM
+			users
+		ORDER BY
+			created_at,
+			id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan user: %v", err)
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing users: %v", err)
+	}
+
+	return users, nil
+}
+
+// ListByIDs lists users with ids, unknown ids are skipped
+func (r *UserRepo) ListByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	query := `
+		SELECT
+			id,
+			name,
+			email,
+			created_at,
+			updated_at
+		FROM
+			users
+		WHERE
+			id = ANY($1::uuid[])
+		ORDER BY
+			created_at,
+			id
+	`
+
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, id.String())
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(values))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan user: %v", err)
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing users by ids: %v", err)
+	}
+
+	return users, nil
+}
+
+func userSubscriptionIDs(ctx context.Context, tx *sql.Tx, userID uuid.UUID) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM subscription_record WHERE user_id = $1 ORDER BY id FOR UPDATE", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("Failed to scan subscription record id: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// userSharedSubscriptionIDs locks records of other users the user is member of
+func userSharedSubscriptionIDs(ctx context.Context, tx *sql.Tx, userID uuid.UUID) ([]int, error) {
+	query := `
+		SELECT
+			id
+		FROM
+			subscription_record
+		WHERE
+			user_id <> $1
+			AND id IN (SELECT subscription_id FROM subscription_member WHERE user_id = $1)
+		ORDER BY
+			id
+		FOR UPDATE
+	`
+
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("Failed to scan subscription record id: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// removeMember removes user from members of subscription record, the change is audited like any update
+func removeMember(ctx context.Context, tx *sql.Tx, subscriptionID int, userID uuid.UUID) error {
+	before, err := snapshotSubscription(ctx, tx, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM subscription_member WHERE subscription_id = $1 AND user_id = $2", subscriptionID, userID)
+	if err != nil {
+		return fmt.Errorf("Failed to remove subscription member: %v", err)
+	}
+
+	after, err := snapshotSubscription(ctx, tx, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	return recordChange(ctx, tx, models.AuditOperationUpdate, subscriptionID, before, after)
+}
+
+// provisionUser creates user with given id unless it exists
+func provisionUser(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO users (id) VALUES ($1) ON CONFLICT DO NOTHING", id)
+	if err != nil {
+		return fmt.Errorf("Failed to provision user: %v", err)
+	}
+
+	return nil
+}
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// isViolation reports whether err is postgres error with given SQLSTATE code
+func isViolation(err error, code string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == code
+}
@@ -0,0 +1,433 @@
+package repository
+
+import (
+	"Effective-Mobile-Test/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type WebhookRepositoryInterface interface {
+	Create(ctx context.Context, webhook *models.Webhook) error
+	GetByID(ctx context.Context, id int) (*models.Webhook, error)
+	Update(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error)
+	DeleteByID(ctx context.Context, id int) error
+	List(ctx context.Context) ([]*models.Webhook, error)
+	ListDeliveries(ctx context.Context, webhookID int) ([]*models.WebhookDelivery, error)
+	EnqueueNotifications(ctx context.Context, from, to time.Time) (int64, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, statusCode int) error
+	MarkFailed(ctx context.Context, id int64, statusCode *int, reason string, nextAttemptAt *time.Time) error
+}
+
+type WebhookRepo struct {
+	db *sql.DB
+}
+
+func NewWebhookRepo(db *sql.DB) WebhookRepositoryInterface {
+	return &WebhookRepo{db: db}
+}
+
+func (r *WebhookRepo) Create(ctx context.Context, webhook *models.Webhook) error {
+	query := `
+		INSERT INTO
+			webhook (
+				url,
+				secret,
+				events
+			)
+		VALUES
+			($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		webhook.URL,
+		webhook.Secret,
+		pq.Array(webhook.Events),
+	).Scan(&webhook.ID, &webhook.CreatedAt)
+}
+
+func (r *WebhookRepo) GetByID(ctx context.Context, id int) (*models.Webhook, error) {
+	query := `
+		SELECT
+			id,
+			url,
+			secret,
+			events,
+			created_at
+		FROM
+			webhook
+		WHERE
+			id = $1
+	`
+
+	var webhook models.Webhook
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		pq.Array(&webhook.Events),
+		&webhook.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (r *WebhookRepo) Update(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	query := `
+		UPDATE webhook
+		SET
+			url = $1,
+			secret = COALESCE(NULLIF($2, ''), secret),
+			events = $3
+		WHERE id = $4
+		RETURNING
+			url,
+			secret,
+			events,
+			created_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		webhook.URL,
+		webhook.Secret,
+		pq.Array(webhook.Events),
+		webhook.ID,
+	).Scan(
+		&webhook.URL,
+		&webhook.Secret,
+		pq.Array(&webhook.Events),
+		&webhook.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("Webhook with id %d not found", webhook.ID)
+		}
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhookRepo) DeleteByID(ctx context.Context, id int) error {
+	query := `
+		DELETE FROM webhook
+		WHERE id = $1
+	`
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return fmt.Errorf("No webhook with id: %d", id)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) List(ctx context.Context) ([]*models.Webhook, error) {
+	query := `
+		SELECT
+			id,
+			url,
+			secret,
+			events,
+			created_at
+		FROM
+			webhook
+		ORDER BY
+			id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []*models.Webhook
+	for rows.Next() {
+		var webhook models.Webhook
+
+		err := rows.Scan(
+			&webhook.ID,
+			&webhook.URL,
+			&webhook.Secret,
+			pq.Array(&webhook.Events),
+			&webhook.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan webhook while listing: %v", err)
+		}
+
+		webhooks = append(webhooks, &webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing webhooks: %v", err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, webhookID int) ([]*models.WebhookDelivery, error) {
+	query := `
+		SELECT
+			id,
+			webhook_id,
+			event,
+			subscription_id,
+			occurs_on,
+			payload,
+			status,
+			attempts,
+			next_attempt_at,
+			last_error,
+			last_status_code,
+			delivered_at,
+			created_at
+		FROM
+			webhook_delivery
+		WHERE
+			webhook_id = $1
+		ORDER BY
+			id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.Event,
+			&delivery.SubscriptionID,
+			&delivery.OccursOn,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.LastError,
+			&delivery.LastStatusCode,
+			&delivery.DeliveredAt,
+			&delivery.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan webhook delivery: %v", err)
+		}
+
+		deliveries = append(deliveries, &delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// EnqueueNotifications creates pending deliveries for every webhook about subscriptions renewing or ending
+// between from and to. Each renewal or end is enqueued once per webhook, repeated calls skip existing deliveries.
+// Auto-renewing subscriptions are not reported as ending
+func (r *WebhookRepo) EnqueueNotifications(ctx context.Context, from, to time.Time) (int64, error) {
+	query := `
+		WITH upcoming AS (
+			SELECT
+				'subscription.renewing' AS event,
+				s.id AS subscription_id,
+				charge.on_date AS occurs_on,
+				s.service_name,
+				s.price,
+				s.user_id,
+				s.start_date,
+				s.end_date
+			FROM
+				subscription_record s
+				CROSS JOIN LATERAL subscription_charge_dates(s.start_date, s.end_date, s.billing_day, $1::date, $2::date) AS charge(on_date)
+			WHERE
+				charge.on_date > s.start_date
+			UNION ALL
+			SELECT
+				'subscription.ending',
+				s.id,
+				s.end_date,
+				s.service_name,
+				s.price,
+				s.user_id,
+				s.start_date,
+				s.end_date
+			FROM
+				subscription_record s
+			WHERE
+				s.end_date BETWEEN $1::date AND $2::date
+				AND NOT s.auto_renew
+		)
+		INSERT INTO
+			webhook_delivery (
+				webhook_id,
+				event,
+				subscription_id,
+				occurs_on,
+				payload
+			)
+		SELECT
+			w.id,
+			u.event,
+			u.subscription_id,
+			u.occurs_on,
+			jsonb_build_object(
+				'event', u.event,
+				'occurs_on', to_char(u.occurs_on, 'YYYY-MM-DD'),
+				'subscription', jsonb_build_object(
+					'id', u.subscription_id,
+					'service_name', u.service_name,
+					'price', u.price,
+					'user_id', u.user_id,
+					'start_date', to_char(u.start_date, 'MM-YYYY'),
+					'end_date', to_char(u.end_date, 'MM-YYYY')
+				)
+			)
+		FROM
+			upcoming u
+			JOIN webhook w ON cardinality(w.events) = 0 OR u.event = ANY(w.events)
+		ON CONFLICT (webhook_id, event, subscription_id, occurs_on) DO NOTHING
+	`
+
+	res, err := r.db.ExecContext(ctx, query, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to enqueue webhook notifications: %v", err)
+	}
+
+	return res.RowsAffected()
+}
+
+// ClaimDueDeliveries returns pending deliveries whose attempt is due and postpones their next attempt by lease,
+// so other instances don't pick them up while they are being delivered
+func (r *WebhookRepo) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_delivery d
+		SET
+			next_attempt_at = now() + $2 * interval '1 millisecond'
+		FROM
+			webhook w
+		WHERE
+			w.id = d.webhook_id
+			AND d.id IN (
+				SELECT
+					id
+				FROM
+					webhook_delivery
+				WHERE
+					status = 'pending'
+					AND next_attempt_at <= now()
+				ORDER BY
+					next_attempt_at,
+					id
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+		RETURNING
+			d.id,
+			d.webhook_id,
+			w.url,
+			w.secret,
+			d.event,
+			d.subscription_id,
+			d.occurs_on,
+			d.payload,
+			d.attempts
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to claim webhook deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.URL,
+			&delivery.Secret,
+			&delivery.Event,
+			&delivery.SubscriptionID,
+			&delivery.OccursOn,
+			&delivery.Payload,
+			&delivery.Attempts,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan claimed webhook delivery: %v", err)
+		}
+
+		deliveries = append(deliveries, &delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while claiming webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	query := `
+		UPDATE webhook_delivery
+		SET
+			status = 'delivered',
+			attempts = attempts + 1,
+			last_status_code = $2,
+			last_error = NULL,
+			delivered_at = now()
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, statusCode)
+	return err
+}
+
+// MarkFailed records failed attempt. Delivery is retried at nextAttemptAt or, when it is nil, given up
+func (r *WebhookRepo) MarkFailed(ctx context.Context, id int64, statusCode *int, reason string, nextAttemptAt *time.Time) error {
+	query := `
+		UPDATE webhook_delivery
+		SET
+			status = CASE WHEN $4::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			attempts = attempts + 1,
+			last_status_code = $2,
+			last_error = $3,
+			next_attempt_at = COALESCE($4, next_attempt_at)
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, statusCode, reason, nextAttemptAt)
+	return err
+}
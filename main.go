@@ -2,13 +2,24 @@ package main
 
 import (
 	"Effective-Mobile-Test/internal/config"
+	"Effective-Mobile-Test/internal/events"
+	"Effective-Mobile-Test/internal/graphqlserver"
+	"Effective-Mobile-Test/internal/grpcserver"
 	"Effective-Mobile-Test/internal/handlers"
+	"Effective-Mobile-Test/internal/models"
+	"Effective-Mobile-Test/internal/notifier"
+	"Effective-Mobile-Test/internal/outbox"
+	"Effective-Mobile-Test/internal/renewal"
 	"Effective-Mobile-Test/internal/repository"
 	"Effective-Mobile-Test/pkg/database"
+	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	_ "Effective-Mobile-Test/docs"
 
@@ -52,11 +63,98 @@ func main() {
 		log.Error("Failed to connect to database", "error", err)
 	}
 
-	repo := repository.NewSubscriptionRepo(appDB)
-	handler := handlers.NewSubscriptionHandler(repo, log)
+	repo := repository.NewSubscriptionRepo(appDB, cfg)
+	budgetRepo := repository.NewBudgetRepo(appDB)
+	dateFormat, err := models.ParseDateFormat(cfg.DateFormat, models.DateFormatMonth)
+	if err != nil {
+		log.Warn("Invalid date format in environment, using default", "value", cfg.DateFormat, "default", models.DateFormatMonth)
+		dateFormat = models.DateFormatMonth
+	}
+	handler := handlers.NewSubscriptionHandler(repo, dateFormat, log)
+	budgetHandler := handlers.NewBudgetHandler(budgetRepo, log)
+
+	auditRepo := repository.NewAuditRepo(appDB)
+	auditHandler := handlers.NewAuditHandler(auditRepo, log)
+
+	userRepo := repository.NewUserRepo(appDB)
+	userHandler := handlers.NewUserHandler(userRepo, log)
+
+	tagRepo := repository.NewTagRepo(appDB)
+	tagHandler := handlers.NewTagHandler(tagRepo, log)
+
+	serviceRepo := repository.NewServiceRepo(appDB)
+	serviceHandler := handlers.NewServiceHandler(serviceRepo, log)
+
+	analyticsRepo := repository.NewAnalyticsRepo(appDB)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsRepo, log)
+
+	webhookRepo := repository.NewWebhookRepo(appDB)
+	webhookHandler := handlers.NewWebhookHandler(webhookRepo, log)
+
+	renewalRepo := repository.NewRenewalRepo(appDB)
+	renewalHandler := handlers.NewRenewalHandler(renewalRepo, log)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go notifier.New(webhookRepo, cfg, log).Run(ctx)
+	go renewal.NewScheduler(renewalRepo, cfg.RenewalInterval, log).Run(ctx)
+
+	outboxRepo := repository.NewOutboxRepo(appDB)
+	sink, err := outbox.NewSinkFromConfig(cfg)
+	if err != nil {
+		log.Error("Failed to create outbox sink", "error", err)
+	} else if sink != nil {
+		go outbox.NewRelay(outboxRepo, sink, cfg.OutboxInterval, log).Run(ctx)
+	}
+
+	listener, err := database.NewListener(cfg, events.Channel, log)
+	if err != nil {
+		log.Error("Failed to listen subscription events", "error", err)
+	}
+
+	hub := events.NewHub(outboxRepo, listener, log)
+	go func() {
+		if err := hub.Run(ctx); err != nil {
+			log.Error("Subscription events hub stopped", "error", err)
+		}
+	}()
+	eventsHandler := handlers.NewEventsHandler(hub, outboxRepo, log)
+
+	grpcServer := grpcserver.NewGRPCServer(grpcserver.New(repo, dateFormat, log))
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		log.Error("Failed to listen gRPC port", "port", cfg.GRPCPort, "error", err)
+		os.Exit(1)
+	}
+	go func() {
+		log.Info("gRPC server started", "port", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Error("gRPC server stopped", "error", err)
+		}
+	}()
 
 	router := mux.NewRouter()
-	handler.RegisterRoutes(router)
+	router.Use(handlers.RequestMetaMiddleware)
+	handlers.RegisterVersionedRoutes(router, cfg.LegacyAPISunset,
+		handler,
+		auditHandler,
+		webhookHandler,
+		eventsHandler,
+		budgetHandler,
+		analyticsHandler,
+		userHandler,
+		tagHandler,
+		serviceHandler,
+		renewalHandler,
+	)
+
+	graphqlHandler, err := graphqlserver.New(repo, userRepo, dateFormat, log)
+	if err != nil {
+		log.Error("Failed to create GraphQL handler", "error", err)
+	} else {
+		graphqlHandler.RegisterRoutes(router)
+	}
 
 	router.PathPrefix("/swagger/").Handler(httpSwagger.Handler(
 		httpSwagger.URL("doc.json"),
@@ -67,6 +165,14 @@ func main() {
 		Handler: router,
 	}
 
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+		if err := server.Shutdown(context.Background()); err != nil {
+			log.Error("Failed to shutdown server", "error", err)
+		}
+	}()
+
 	server.ListenAndServe()
 	log.Info("Server started", "port", cfg.ServerPort)
 
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS subscription_audit;
\ No newline at end of file
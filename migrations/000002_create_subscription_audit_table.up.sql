@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS subscription_audit (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    subscription_id INT NOT NULL,
+    operation TEXT NOT NULL CHECK(operation IN ('create', 'update', 'delete')),
+    actor TEXT NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    before JSONB,
+    after JSONB
+);
+
+CREATE INDEX IF NOT EXISTS subscription_audit_subscription_id_idx ON subscription_audit (subscription_id, changed_at);
+CREATE INDEX IF NOT EXISTS subscription_audit_actor_idx ON subscription_audit (actor, changed_at);
+CREATE INDEX IF NOT EXISTS subscription_audit_changed_at_idx ON subscription_audit (changed_at);
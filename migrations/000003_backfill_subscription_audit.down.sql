@@ -0,0 +1 @@
+DELETE FROM subscription_audit WHERE actor = 'system' AND request_id = '' AND operation = 'create';
\ No newline at end of file
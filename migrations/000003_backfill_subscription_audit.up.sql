@@ -0,0 +1,15 @@
+-- Records existing before auditing get a synthetic creation entry. It is dated by start of record, or now
+-- for records starting in the future, so as_of queries before the migration still see them
+INSERT INTO subscription_audit (subscription_id, operation, actor, changed_at, after)
+SELECT
+    s.id,
+    'create',
+    'system',
+    LEAST(s.start_date::timestamptz, now()),
+    to_jsonb(s)
+FROM
+    subscription_record s
+WHERE
+    NOT EXISTS (
+        SELECT 1 FROM subscription_audit a WHERE a.subscription_id = s.id
+    );
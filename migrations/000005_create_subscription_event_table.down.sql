@@ -0,0 +1 @@
+DROP TABLE IF EXISTS subscription_event;
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS subscription_event (
+    seq BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    type TEXT NOT NULL,
+    subscription_id INT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS subscription_event_unpublished_idx ON subscription_event (seq) WHERE published_at IS NULL;
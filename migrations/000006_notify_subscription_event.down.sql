@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS subscription_event_notify ON subscription_event;
+DROP FUNCTION IF EXISTS notify_subscription_event();
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE OR REPLACE FUNCTION notify_subscription_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('subscription_event', NEW.seq::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER subscription_event_notify
+    AFTER INSERT ON subscription_event
+    FOR EACH ROW EXECUTE FUNCTION notify_subscription_event();
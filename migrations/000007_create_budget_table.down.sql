@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS budget_alert;
+DROP TABLE IF EXISTS budget;
\ No newline at end of file
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS budget (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id UUID,
+    service_name TEXT,
+    monthly_limit INT NOT NULL CHECK(monthly_limit >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK(user_id IS NOT NULL OR service_name IS NOT NULL),
+    UNIQUE NULLS NOT DISTINCT (user_id, service_name)
+);
+
+CREATE TABLE IF NOT EXISTS budget_alert (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    budget_id INT NOT NULL REFERENCES budget (id) ON DELETE CASCADE,
+    subscription_id INT NOT NULL,
+    month DATE NOT NULL,
+    monthly_limit INT NOT NULL,
+    spend INT NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS budget_alert_budget_id_idx ON budget_alert (budget_id, created_at);
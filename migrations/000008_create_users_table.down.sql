@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS deleted_subscription_record;
+
+DROP INDEX IF EXISTS subscription_record_user_id_idx;
+
+ALTER TABLE budget DROP CONSTRAINT IF EXISTS budget_user_id_fkey;
+
+ALTER TABLE subscription_record DROP CONSTRAINT IF EXISTS subscription_record_user_id_fkey;
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,32 @@
+-- user is a reserved word, so unlike other tables this one is plural
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT,
+    email TEXT UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO users (id)
+SELECT user_id FROM subscription_record
+UNION
+SELECT user_id FROM budget WHERE user_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE subscription_record
+    ADD CONSTRAINT subscription_record_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE budget
+    ADD CONSTRAINT budget_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS subscription_record_user_id_idx ON subscription_record (user_id);
+
+-- Subscription records of removed users, kept as they were at removal
+CREATE TABLE IF NOT EXISTS deleted_subscription_record (
+    id INT PRIMARY KEY,
+    user_id UUID NOT NULL,
+    record JSONB NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS deleted_subscription_record_user_id_idx ON deleted_subscription_record (user_id);
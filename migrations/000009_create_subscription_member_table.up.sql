@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS subscription_member (
+    subscription_id INT NOT NULL REFERENCES subscription_record(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    weight INT NOT NULL DEFAULT 1 CHECK(weight > 0),
+    PRIMARY KEY (subscription_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS subscription_member_user_id_idx ON subscription_member (user_id);
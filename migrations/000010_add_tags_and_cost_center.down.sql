@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS subscription_record_cost_center_idx;
+
+DROP INDEX IF EXISTS subscription_record_tags_idx;
+
+ALTER TABLE subscription_record
+    DROP COLUMN IF EXISTS cost_center,
+    DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,7 @@
+ALTER TABLE subscription_record
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS cost_center TEXT;
+
+CREATE INDEX IF NOT EXISTS subscription_record_tags_idx ON subscription_record USING GIN (tags);
+
+CREATE INDEX IF NOT EXISTS subscription_record_cost_center_idx ON subscription_record (cost_center);
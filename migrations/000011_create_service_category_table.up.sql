@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS service_category (
+    service_name TEXT PRIMARY KEY,
+    category TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS service_category_category_idx ON service_category (category);
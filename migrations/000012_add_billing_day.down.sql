@@ -0,0 +1,10 @@
+DROP FUNCTION IF EXISTS subscription_charge_count(DATE, DATE, INT, DATE, DATE);
+
+DROP FUNCTION IF EXISTS subscription_charge_dates(DATE, DATE, INT, DATE, DATE);
+
+UPDATE subscription_record
+SET end_date = GREATEST(date_trunc('month', end_date)::date, start_date)
+WHERE end_date IS NOT NULL;
+
+ALTER TABLE subscription_record
+    DROP COLUMN IF EXISTS billing_day;
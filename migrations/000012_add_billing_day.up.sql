@@ -0,0 +1,68 @@
+ALTER TABLE subscription_record
+    ADD COLUMN IF NOT EXISTS billing_day SMALLINT CHECK(billing_day BETWEEN 1 AND 31);
+
+UPDATE subscription_record
+SET billing_day = EXTRACT(DAY FROM start_date)
+WHERE billing_day IS NULL;
+
+ALTER TABLE subscription_record
+    ALTER COLUMN billing_day SET NOT NULL;
+
+-- End dates used to be stored as the first day of the last paid month, with day precision
+-- subscription ends at the last day of that month instead
+UPDATE subscription_record
+SET end_date = (date_trunc('month', end_date) + interval '1 month' - interval '1 day')::date
+WHERE end_date IS NOT NULL;
+
+-- Snapshots in audit log keep their original shape, they are converted when read for as_of queries
+
+-- Billing dates of subscription within window: billing_day of every month, or the last day of months
+-- shorter than that, not before start and not after end of subscription. Window defaults to the whole
+-- subscription up to the current date
+CREATE OR REPLACE FUNCTION subscription_charge_dates(
+    start_date DATE,
+    end_date DATE,
+    billing_day INT,
+    window_start DATE,
+    window_end DATE
+) RETURNS SETOF DATE
+LANGUAGE sql STABLE
+AS $$
+    SELECT
+        charge.on_date
+    FROM
+        generate_series(
+            date_trunc('month', GREATEST(start_date, COALESCE(window_start, start_date))),
+            date_trunc('month', LEAST(COALESCE(end_date, 'infinity'::date), COALESCE(window_end, current_date))),
+            interval '1 month'
+        ) AS month(first_day)
+        CROSS JOIN LATERAL (
+            SELECT
+                (
+                    month.first_day + (
+                        LEAST(
+                            COALESCE(billing_day, EXTRACT(DAY FROM start_date)::int),
+                            EXTRACT(DAY FROM month.first_day + interval '1 month' - interval '1 day')::int
+                        ) - 1
+                    ) * interval '1 day'
+                )::date AS on_date
+        ) charge
+    WHERE
+        charge.on_date >= GREATEST(start_date, COALESCE(window_start, start_date))
+        AND charge.on_date <= LEAST(COALESCE(end_date, 'infinity'::date), COALESCE(window_end, current_date))
+$$;
+
+CREATE OR REPLACE FUNCTION subscription_charge_count(
+    start_date DATE,
+    end_date DATE,
+    billing_day INT,
+    window_start DATE,
+    window_end DATE
+) RETURNS INT
+LANGUAGE sql STABLE
+AS $$
+    SELECT
+        COUNT(*)::int
+    FROM
+        subscription_charge_dates(start_date, end_date, billing_day, window_start, window_end)
+$$;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS renewal_run;
+
+DROP INDEX IF EXISTS subscription_record_status_end_date_idx;
+
+ALTER TABLE subscription_record
+    DROP COLUMN IF EXISTS auto_renew,
+    DROP COLUMN IF EXISTS status;
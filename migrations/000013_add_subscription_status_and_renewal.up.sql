@@ -0,0 +1,21 @@
+ALTER TABLE subscription_record
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active' CHECK(status IN ('active', 'ended')),
+    ADD COLUMN IF NOT EXISTS auto_renew BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE subscription_record
+SET status = 'ended'
+WHERE end_date < current_date;
+
+CREATE INDEX IF NOT EXISTS subscription_record_status_end_date_idx ON subscription_record (status, end_date);
+
+CREATE TABLE IF NOT EXISTS renewal_run (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ended INT NOT NULL DEFAULT 0,
+    renewed INT NOT NULL DEFAULT 0,
+    conflicting_ids INT[] NOT NULL DEFAULT '{}',
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS renewal_run_started_at_idx ON renewal_run (started_at);
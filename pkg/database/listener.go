@@ -0,0 +1,27 @@
+package database
+
+import (
+	"Effective-Mobile-Test/internal/config"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// NewListener opens dedicated connection listening for Postgres notifications on channel,
+// reconnecting automatically when connection is lost
+func NewListener(cfg *config.Config, channel string, log *slog.Logger) (*pq.Listener, error) {
+	listener := pq.NewListener(ConnectionString(cfg), time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Warn("Postgres listener connection problem", "channel", channel, "error", err)
+		}
+	})
+
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("Failed to listen channel %s: %v", channel, err)
+	}
+
+	return listener, nil
+}
@@ -6,13 +6,15 @@ import (
 	"fmt"
 )
 
-func NewPostgresDB(cfg *config.Config) (*sql.DB, error) {
-	conStr := fmt.Sprintf(
+func ConnectionString(cfg *config.Config) string {
+	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName,
 	)
+}
 
-	db, err := sql.Open("postgres", conStr)
+func NewPostgresDB(cfg *config.Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", ConnectionString(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("Failed to open database: %v", err)
 	}
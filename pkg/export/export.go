@@ -0,0 +1,190 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+)
+
+var contentTypes = map[string]string{
+	FormatJSON:   "application/json",
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Writer writes table rows one by one in a specific format. Every writer must be finished either by
+// Close, which completes output, or by Abort, which gives up on it and releases resources
+type Writer interface {
+	WriteRow(values []any) error
+	Close() error
+	Abort()
+}
+
+// NegotiateFormat picks export format from format query parameter or, if it is absent, from Accept header
+func NegotiateFormat(r *http.Request) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		format = strings.ToLower(format)
+		if _, exists := contentTypes[format]; !exists {
+			return "", fmt.Errorf("Unsupported format %s, must be one of json, csv, ndjson, xlsx", format)
+		}
+		return format, nil
+	}
+
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.Split(accepted, ";")[0])
+		for format, contentType := range contentTypes {
+			if mediaType == strings.Split(contentType, ";")[0] {
+				return format, nil
+			}
+		}
+	}
+
+	return FormatJSON, nil
+}
+
+// NewWriter sets response headers for given format and returns writer that streams rows into response.
+// Header holds column names, they are written as the first row or used as keys of NDJSON objects
+func NewWriter(w http.ResponseWriter, format, filename string, header []string) (Writer, error) {
+	w.Header().Set("Content-Type", contentTypes[format])
+	if format != FormatJSON {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"."+format))
+	}
+
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, header)
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w), header: header}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, header)
+	default:
+		return nil, fmt.Errorf("Format %s can not be streamed by rows", format)
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, header []string) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &csvWriter{writer: writer}, nil
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		if value != nil {
+			record[i] = fmt.Sprint(value)
+		}
+	}
+
+	if err := c.writer.Write(record); err != nil {
+		return err
+	}
+
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvWriter) Abort() {}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+	header  []string
+}
+
+func (n *ndjsonWriter) WriteRow(values []any) error {
+	object := make(map[string]any, len(values))
+	for i, value := range values {
+		object[n.header[i]] = value
+	}
+
+	return n.encoder.Encode(object)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+func (n *ndjsonWriter) Abort() {}
+
+// xlsxWriter keeps written rows in temporary file of excelize stream writer
+// and sends the workbook once all rows are written, as XLSX is a zip archive
+type xlsxWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, header []string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	writer := &xlsxWriter{w: w, file: file, stream: stream}
+
+	values := make([]any, len(header))
+	for i, column := range header {
+		values[i] = column
+	}
+	if err := writer.WriteRow(values); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+func (x *xlsxWriter) WriteRow(values []any) error {
+	x.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+
+	return x.stream.SetRow(cell, values)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+
+	return x.file.Write(x.w)
+}
+
+// Abort drops workbook without sending it, removing temporary file of stream writer
+func (x *xlsxWriter) Abort() {
+	x.file.Close()
+}
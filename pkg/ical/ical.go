@@ -0,0 +1,122 @@
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Calendar is a minimal RFC 5545 calendar of all-day events
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Date        time.Time
+	// RRule is recurrence rule value without "RRULE:" prefix, empty for single events
+	RRule string
+}
+
+func (c *Calendar) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+escape(c.ProdID))
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+escape(c.Name))
+	}
+
+	for _, event := range c.Events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+escape(event.UID))
+		writeLine(&b, "DTSTAMP:"+stamp)
+		writeLine(&b, "DTSTART;VALUE=DATE:"+event.Date.Format("20060102"))
+		writeLine(&b, "DTEND;VALUE=DATE:"+event.Date.AddDate(0, 0, 1).Format("20060102"))
+		if event.RRule != "" {
+			writeLine(&b, "RRULE:"+event.RRule)
+		}
+		writeLine(&b, "SUMMARY:"+escape(event.Summary))
+		if event.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escape(event.Description))
+		}
+		writeLine(&b, "TRANSP:TRANSPARENT")
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// MonthlyOnDayUntil returns monthly recurrence rule on day of month, or on the last day of months
+// shorter than that, ending at until date when it is not nil
+func MonthlyOnDayUntil(day int, until *time.Time) string {
+	days := []string{strconv.Itoa(day)}
+	for shorter := day - 1; shorter >= 28; shorter-- {
+		days = append([]string{strconv.Itoa(shorter)}, days...)
+	}
+
+	rule := fmt.Sprintf("FREQ=MONTHLY;BYMONTHDAY=%s;BYSETPOS=-1", strings.Join(days, ","))
+	if until != nil {
+		rule += ";UNTIL=" + until.Format("20060102")
+	}
+
+	return rule
+}
+
+func escape(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+
+	return replacer.Replace(text)
+}
+
+// writeLine writes content line folded to 75 octets as required by RFC 5545,
+// continuation lines start with a space that counts towards the limit
+func writeLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := runeBoundary(line, limit)
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func runeBoundary(s string, limit int) int {
+	if len(s) <= limit {
+		return len(s)
+	}
+
+	cut := limit
+	for cut > 0 && !isRuneStart(s[cut]) {
+		cut--
+	}
+
+	return cut
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}